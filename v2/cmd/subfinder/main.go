@@ -1,13 +1,21 @@
 package main
 
 import (
+	"os"
+
 	"github.com/YouChenJun/subfinder-plus/pkg/runner"
+	"github.com/YouChenJun/subfinder-plus/pkg/server"
 	// Attempts to increase the OS file descriptors - Fail silently
 	_ "github.com/projectdiscovery/fdmax/autofdmax"
 	"github.com/projectdiscovery/gologger"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve()
+		return
+	}
+
 	// Parse the command line flags and read config files
 	options := runner.ParseOptions()
 
@@ -17,7 +25,21 @@ func main() {
 	}
 
 	err = newRunner.RunEnumeration()
+	newRunner.Close()
 	if err != nil {
 		gologger.Fatal().Msgf("Could not run enumeration: %s\n", err)
 	}
 }
+
+// serve runs subfinder as an HTTP API server
+func serve() {
+	options := server.ParseOptions(os.Args[2:])
+
+	apiServer, err := server.New(options)
+	if err != nil {
+		gologger.Fatal().Msgf("Could not create server: %s\n", err)
+	}
+	if err := apiServer.Start(); err != nil {
+		gologger.Fatal().Msgf("Could not run server: %s\n", err)
+	}
+}
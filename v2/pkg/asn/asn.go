@@ -0,0 +1,154 @@
+// Package asn maps IP addresses to the autonomous systems announcing them,
+// using a local IP-to-ASN database loaded into an in-memory range index.
+package asn
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+)
+
+// Info contains the autonomous system an IP address belongs to
+type Info struct {
+	IP      string
+	Number  uint32
+	Name    string
+	Country string
+	// CIDR is the network of the database containing the IP address
+	CIDR string
+}
+
+// ipRange is a range of addresses announced by an autonomous system.
+// The prefix is only known for the ranges loaded from a MMDB file.
+type ipRange struct {
+	start, end netip.Addr
+	prefix     netip.Prefix
+	info       *Info
+}
+
+// Database is an IP-to-ASN database indexed by address ranges
+type Database struct {
+	ranges []ipRange
+}
+
+// Load loads the IP-to-ASN database file, which is either a MMDB file or an
+// iptoasn TSV file (range_start, range_end, AS_number, country_code,
+// AS_description), optionally gzip compressed
+func Load(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var data io.Reader = reader
+	if magic, _ := reader.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		data = gzipReader
+	}
+
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []ipRange
+	if bytes.Contains(content, mmdbMetadataMarker) {
+		ranges, err = parseMMDB(content)
+	} else {
+		ranges, err = parseTSV(bytes.NewReader(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", path, err)
+	}
+	return newDatabase(ranges), nil
+}
+
+func newDatabase(ranges []ipRange) *Database {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Less(ranges[j].start)
+	})
+	return &Database{ranges: ranges}
+}
+
+// Len returns the number of address ranges of the database
+func (d *Database) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.ranges)
+}
+
+// Lookup returns the autonomous systems of the IP addresses found in the database
+func (d *Database) Lookup(ips []string) []Info {
+	if d == nil {
+		return nil
+	}
+
+	var infos []Info
+	for _, ip := range ips {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			continue
+		}
+		if info, ok := d.lookup(addr.Unmap()); ok {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// lookup searches the range containing the address
+func (d *Database) lookup(addr netip.Addr) (Info, bool) {
+	// Find the last range starting at or before the address
+	i := sort.Search(len(d.ranges), func(i int) bool {
+		return addr.Less(d.ranges[i].start)
+	}) - 1
+	if i < 0 || d.ranges[i].end.Less(addr) {
+		return Info{}, false
+	}
+
+	r := d.ranges[i]
+	info := *r.info
+	info.IP = addr.String()
+	if r.prefix.IsValid() {
+		info.CIDR = r.prefix.String()
+	} else {
+		info.CIDR = containingPrefix(addr, r.start, r.end).String()
+	}
+	return info, true
+}
+
+// containingPrefix returns the largest network containing the address within the range
+func containingPrefix(addr, start, end netip.Addr) netip.Prefix {
+	for bits := 0; bits <= addr.BitLen(); bits++ {
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			break
+		}
+		if !prefix.Addr().Less(start) && !end.Less(lastAddr(prefix)) {
+			return prefix
+		}
+	}
+	return netip.PrefixFrom(addr, addr.BitLen())
+}
+
+// lastAddr returns the last address of the network
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	raw := prefix.Masked().Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(raw)*8; bit++ {
+		raw[bit/8] |= 0x80 >> (bit % 8)
+	}
+	addr, _ := netip.AddrFromSlice(raw)
+	return addr
+}
@@ -0,0 +1,212 @@
+package asn
+
+import (
+	"compress/gzip"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const testTSV = "1.0.0.0\t1.0.0.255\t13335\tUS\tCLOUDFLARENET\n" +
+	"1.0.1.0\t1.0.3.255\t0\tNone\tNot routed\n" +
+	"8.8.8.0\t8.8.8.255\t15169\tUS\tGOOGLE\n" +
+	"2606:4700::\t2606:4700:ffff:ffff:ffff:ffff:ffff:ffff\t13335\tUS\tCLOUDFLARENET\n" +
+	"10.0.0.16\t10.0.0.47\t64512\tZZ\tPRIVATE\n"
+
+func TestLoadTSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip2asn-combined.tsv")
+	require.Nil(t, os.WriteFile(path, []byte(testTSV), 0644))
+
+	database, err := Load(path)
+	require.Nil(t, err)
+	assert.Equal(t, 4, database.Len())
+
+	infos := database.Lookup([]string{"1.0.0.1", "1.0.2.1", "8.8.8.8", "2606:4700::6810:84e5", "10.0.0.20", "invalid"})
+	assert.Equal(t, []Info{
+		{IP: "1.0.0.1", Number: 13335, Name: "CLOUDFLARENET", Country: "US", CIDR: "1.0.0.0/24"},
+		{IP: "8.8.8.8", Number: 15169, Name: "GOOGLE", Country: "US", CIDR: "8.8.8.0/24"},
+		{IP: "2606:4700::6810:84e5", Number: 13335, Name: "CLOUDFLARENET", Country: "US", CIDR: "2606:4700::/32"},
+		{IP: "10.0.0.20", Number: 64512, Name: "PRIVATE", Country: "ZZ", CIDR: "10.0.0.16/28"},
+	}, infos)
+}
+
+func TestLoadGzipTSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip2asn-v4-u32.tsv.gz")
+	file, err := os.Create(path)
+	require.Nil(t, err)
+	writer := gzip.NewWriter(file)
+	_, err = writer.Write([]byte("134744064\t134744319\t15169\tUS\tGOOGLE\n"))
+	require.Nil(t, err)
+	require.Nil(t, writer.Close())
+	require.Nil(t, file.Close())
+
+	database, err := Load(path)
+	require.Nil(t, err)
+	assert.Equal(t, []Info{{IP: "8.8.8.8", Number: 15169, Name: "GOOGLE", Country: "US", CIDR: "8.8.8.0/24"}}, database.Lookup([]string{"8.8.8.8"}))
+}
+
+func TestLoadMMDB(t *testing.T) {
+	networks := map[string]map[string]any{
+		"1.0.0.0/24": {"autonomous_system_number": uint32(13335), "autonomous_system_organization": "CLOUDFLARENET"},
+		"8.8.8.0/24": {"asn": "AS15169", "name": "Google LLC", "country": "US"},
+	}
+	expected := []Info{
+		{IP: "1.0.0.200", Number: 13335, Name: "CLOUDFLARENET", CIDR: "1.0.0.0/24"},
+		{IP: "8.8.8.8", Number: 15169, Name: "Google LLC", Country: "US", CIDR: "8.8.8.0/24"},
+	}
+
+	for _, test := range []struct {
+		ipVersion  int
+		recordSize int
+	}{{4, 24}, {4, 28}, {4, 32}, {6, 24}, {6, 28}, {6, 32}} {
+		tree := networks
+		infos := expected
+		if test.ipVersion == 6 {
+			tree = map[string]map[string]any{"2606:4700::/32": {"autonomous_system_number": uint32(13335), "autonomous_system_organization": "CLOUDFLARENET"}}
+			maps.Copy(tree, networks)
+			infos = append(slices.Clone(expected), Info{IP: "2606:4700::6810:84e5", Number: 13335, Name: "CLOUDFLARENET", CIDR: "2606:4700::/32"})
+		}
+
+		path := filepath.Join(t.TempDir(), "asn.mmdb")
+		require.Nil(t, os.WriteFile(path, buildMMDB(test.ipVersion, test.recordSize, tree), 0644))
+
+		database, err := Load(path)
+		require.Nil(t, err, test)
+		// The IPv4 networks are only read once, not again under the aliases of ::/96
+		assert.Equal(t, len(tree), database.Len(), test)
+		assert.Equal(t, infos, database.Lookup([]string{"1.0.0.200", "8.8.4.4", "8.8.8.8", "2606:4700::6810:84e5"}), test)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	summary := NewSummary()
+	cloudflare := Info{Number: 13335, Name: "CLOUDFLARENET"}
+	google := Info{Number: 15169, Name: "GOOGLE"}
+	summary.Add("a.example.com", []Info{withIP(cloudflare, "1.0.0.1"), withIP(cloudflare, "1.0.0.2")})
+	summary.Add("b.example.com", []Info{withIP(cloudflare, "1.0.0.1")})
+	summary.Add("c.example.com", []Info{withIP(google, "8.8.8.8")})
+
+	assert.Equal(t, []SummaryEntry{
+		{Number: 13335, Name: "CLOUDFLARENET", Hosts: 2, IPs: 2},
+		{Number: 15169, Name: "GOOGLE", Hosts: 1, IPs: 1},
+	}, summary.Entries())
+}
+
+func withIP(info Info, ip string) Info {
+	info.IP = ip
+	return info
+}
+
+// buildMMDB builds an MMDB file mapping the networks to their data, with a search tree
+// of the given IP version and record size. The IPv4 networks of an IPv6 tree are stored
+// under ::/96, which ::ffff:0:0/96 aliases as in the databases published by MaxMind.
+func buildMMDB(ipVersion, recordSize int, networks map[string]map[string]any) []byte {
+	type node struct{ children [2]int }
+	nodes := []node{{children: [2]int{-1, -1}}}
+	// leaves maps a node record to the data offset of its network, keyed by node index and bit
+	leaves := make(map[[2]int]int)
+
+	// insert creates the path of the network and returns the node and bit of its record
+	insert := func(address [16]byte, bits int) (int, int) {
+		current := 0
+		for depth := 0; ; depth++ {
+			bit := int(address[depth/8]>>(7-depth%8)) & 1
+			if depth == bits-1 {
+				return current, bit
+			}
+			if nodes[current].children[bit] < 0 {
+				nodes = append(nodes, node{children: [2]int{-1, -1}})
+				nodes[current].children[bit] = len(nodes) - 1
+			}
+			current = nodes[current].children[bit]
+		}
+	}
+
+	var data []byte
+	for network, record := range networks {
+		prefix := netip.MustParsePrefix(network)
+		offset := len(data)
+		data = append(data, encodeMMDB(record)...)
+
+		address, bits := prefix.Addr().As16(), prefix.Bits()
+		if ipVersion == 4 {
+			copy(address[:], address[12:])
+		} else if prefix.Addr().Is4() {
+			// As16 maps the IPv4 addresses to ::ffff:0:0/96
+			address[10], address[11] = 0, 0
+			bits += 96
+		}
+		current, bit := insert(address, bits)
+		leaves[[2]int{current, bit}] = offset
+	}
+	if ipVersion == 6 {
+		ipv4Node := 0
+		for depth := 0; depth < 96 && ipv4Node >= 0; depth++ {
+			ipv4Node = nodes[ipv4Node].children[0]
+		}
+		if ipv4Node >= 0 {
+			current, bit := insert(netip.MustParseAddr("::ffff:0:0").As16(), 96)
+			nodes[current].children[bit] = ipv4Node
+		}
+	}
+
+	var content []byte
+	for i, n := range nodes {
+		var records [2]int
+		for bit, child := range n.children {
+			records[bit] = len(nodes) // empty record
+			if offset, ok := leaves[[2]int{i, bit}]; ok {
+				records[bit] = len(nodes) + mmdbDataSeparator + offset
+			} else if child >= 0 {
+				records[bit] = child
+			}
+		}
+		left, right := records[0], records[1]
+		switch recordSize {
+		case 24:
+			content = append(content, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			content = append(content, byte(left>>16), byte(left>>8), byte(left), byte(left>>20&0xf0|right>>24&0x0f), byte(right>>16), byte(right>>8), byte(right))
+		case 32:
+			content = append(content, byte(left>>24), byte(left>>16), byte(left>>8), byte(left), byte(right>>24), byte(right>>16), byte(right>>8), byte(right))
+		}
+	}
+	content = append(content, make([]byte, mmdbDataSeparator)...)
+	content = append(content, data...)
+	content = append(content, mmdbMetadataMarker...)
+	content = append(content, encodeMMDB(map[string]any{
+		"node_count":  uint32(len(nodes)),
+		"record_size": uint16(recordSize),
+		"ip_version":  uint16(ipVersion),
+	})...)
+	return content
+}
+
+// encodeMMDB encodes the maps, strings and unsigned integers used by the tests
+func encodeMMDB(value any) []byte {
+	switch value := value.(type) {
+	case map[string]any:
+		encoded := []byte{byte(mmdbMap<<5 | len(value))}
+		for key, item := range value {
+			encoded = append(encoded, encodeMMDB(key)...)
+			encoded = append(encoded, encodeMMDB(item)...)
+		}
+		return encoded
+	case string:
+		if len(value) >= 29 {
+			return append([]byte{byte(mmdbString<<5 | 29), byte(len(value) - 29)}, value...)
+		}
+		return append([]byte{byte(mmdbString<<5 | len(value))}, value...)
+	case uint16:
+		return []byte{byte(mmdbUint16<<5 | 2), byte(value >> 8), byte(value)}
+	case uint32:
+		return []byte{byte(mmdbUint32<<5 | 4), byte(value >> 24), byte(value >> 16), byte(value >> 8), byte(value)}
+	}
+	panic("unsupported type")
+}
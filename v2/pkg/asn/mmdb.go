@@ -0,0 +1,393 @@
+package asn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// mmdbMetadataMarker precedes the metadata at the end of a MMDB file
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// mmdbDataSeparator is the size of the zeroed separator between the search tree and the data section
+const mmdbDataSeparator = 16
+
+// MMDB data types, see https://maxmind.github.io/MaxMind-DB/
+const (
+	mmdbExtended = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBool
+	mmdbFloat
+)
+
+// parseMMDB walks the whole search tree of a MMDB file and returns a range
+// for every network it contains, with the AS details of its data record
+func parseMMDB(content []byte) ([]ipRange, error) {
+	markerIndex := bytes.LastIndex(content, mmdbMetadataMarker)
+	if markerIndex < 0 {
+		return nil, errors.New("metadata not found")
+	}
+	metadataDecoder := &mmdbDecoder{data: content[markerIndex+len(mmdbMetadataMarker):]}
+	value, _, err := metadataDecoder.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %s", err)
+	}
+	metadata, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid metadata")
+	}
+
+	tree := &mmdbTree{
+		nodeCount:  uint(toUint(metadata["node_count"])),
+		recordSize: uint(toUint(metadata["record_size"])),
+		ipVersion:  uint(toUint(metadata["ip_version"])),
+		infos:      make(map[uint]*Info),
+	}
+	if tree.recordSize != 24 && tree.recordSize != 28 && tree.recordSize != 32 {
+		return nil, fmt.Errorf("unsupported record size %d", tree.recordSize)
+	}
+	treeSize := tree.nodeCount * tree.recordSize / 4
+	if treeSize+mmdbDataSeparator > uint(markerIndex) {
+		return nil, errors.New("invalid search tree size")
+	}
+	tree.nodes = content[:treeSize]
+	tree.data = &mmdbDecoder{data: content[treeSize+mmdbDataSeparator : markerIndex]}
+
+	bitCount := 32
+	if tree.ipVersion == 6 {
+		bitCount = 128
+		// IPv4 addresses are stored under ::/96, which other IPv6 networks alias
+		tree.ipv4Node = 0
+		for i := 0; i < 96 && tree.ipv4Node < tree.nodeCount; i++ {
+			tree.ipv4Node = tree.record(tree.ipv4Node, 0)
+		}
+	}
+
+	if err := tree.walk(0, [16]byte{}, 0, bitCount); err != nil {
+		return nil, err
+	}
+	return tree.ranges, nil
+}
+
+// mmdbTree is the binary search tree of a MMDB file, whose leaves point to the data records
+type mmdbTree struct {
+	nodes      []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Node   uint
+	data       *mmdbDecoder
+	// infos holds the AS details decoded for every data record offset
+	infos  map[uint]*Info
+	ranges []ipRange
+}
+
+// record returns the left (bit 0) or right (bit 1) record of the node
+func (t *mmdbTree) record(node uint, bit int) uint {
+	offset := node * t.recordSize / 4
+	b := t.nodes[offset : offset+t.recordSize/4]
+	switch t.recordSize {
+	case 24:
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		if bit == 0 {
+			return uint(binary.BigEndian.Uint32(b[0:4]))
+		}
+		return uint(binary.BigEndian.Uint32(b[4:8]))
+	}
+}
+
+// walk visits the subtree of the node, whose network has the given address and depth
+func (t *mmdbTree) walk(node uint, address [16]byte, depth, bitCount int) error {
+	for bit := 0; bit < 2; bit++ {
+		next := address
+		if bit == 1 {
+			next[depth/8] |= 0x80 >> (depth % 8)
+		}
+		record := t.record(node, bit)
+
+		switch {
+		case record < t.nodeCount:
+			// The IPv4 subtree is only visited under ::/96, not under its aliases
+			if bitCount == 128 && record == t.ipv4Node && next != ([16]byte{}) {
+				continue
+			}
+			if depth+1 >= bitCount {
+				return errors.New("search tree deeper than the address size")
+			}
+			if err := t.walk(record, next, depth+1, bitCount); err != nil {
+				return err
+			}
+		case record > t.nodeCount:
+			info, err := t.info(record - t.nodeCount - mmdbDataSeparator)
+			if err != nil {
+				return err
+			}
+			if info != nil {
+				t.addRange(next, depth+1, bitCount, info)
+			}
+		}
+	}
+	return nil
+}
+
+// addRange records the network of the given address and prefix length
+func (t *mmdbTree) addRange(address [16]byte, bits, bitCount int, info *Info) {
+	var prefix netip.Prefix
+	if bitCount == 32 {
+		prefix = netip.PrefixFrom(netip.AddrFrom4([4]byte(address[:4])), bits)
+	} else if bits >= 96 && [12]byte(address[:12]) == ([12]byte{}) {
+		prefix = netip.PrefixFrom(netip.AddrFrom4([4]byte(address[12:])), bits-96)
+	} else {
+		prefix = netip.PrefixFrom(netip.AddrFrom16(address), bits)
+	}
+	t.ranges = append(t.ranges, ipRange{start: prefix.Addr(), end: lastAddr(prefix), prefix: prefix, info: info})
+}
+
+// info returns the AS details of the data record at the offset, nil if it has none
+func (t *mmdbTree) info(offset uint) (*Info, error) {
+	if info, ok := t.infos[offset]; ok {
+		return info, nil
+	}
+
+	value, _, err := t.data.decode(offset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data record at %d: %s", offset, err)
+	}
+	info := newMMDBInfo(value)
+	t.infos[offset] = info
+	return info, nil
+}
+
+// newMMDBInfo reads the AS details of a data record, as found in the
+// GeoLite2 ASN and in the ipinfo databases
+func newMMDBInfo(value any) *Info {
+	record, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	info := &Info{}
+	for _, key := range []string{"autonomous_system_number", "asn"} {
+		switch number := record[key].(type) {
+		case string:
+			parsed, _ := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(number), "AS"), 10, 32)
+			info.Number = uint32(parsed)
+		case uint64:
+			info.Number = uint32(number)
+		}
+		if info.Number != 0 {
+			break
+		}
+	}
+	for _, key := range []string{"autonomous_system_organization", "as_name", "name"} {
+		if name, ok := record[key].(string); ok && info.Name == "" {
+			info.Name = name
+		}
+	}
+	for _, key := range []string{"country_code", "country"} {
+		switch country := record[key].(type) {
+		case string:
+			info.Country = country
+		case map[string]any:
+			info.Country, _ = country["iso_code"].(string)
+		}
+		if info.Country != "" {
+			break
+		}
+	}
+
+	if info.Number == 0 {
+		return nil
+	}
+	return info
+}
+
+// mmdbDecoder decodes the values of the data section of a MMDB file
+type mmdbDecoder struct {
+	data []byte
+}
+
+// decode decodes the value at the offset and returns it along with the offset following it
+func (d *mmdbDecoder) decode(offset uint) (any, uint, error) {
+	kind, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if kind == mmdbPointer {
+		pointer, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer)
+		return value, next, err
+	}
+
+	switch kind {
+	case mmdbMap:
+		values := make(map[string]any, size)
+		for i := uint(0); i < size; i++ {
+			var key, value any
+			key, offset, err = d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, offset, err = d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			values[keyString] = value
+		}
+		return values, offset, nil
+	case mmdbArray:
+		values := make([]any, 0, size)
+		for i := uint(0); i < size; i++ {
+			var value any
+			value, offset, err = d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			values = append(values, value)
+		}
+		return values, offset, nil
+	case mmdbBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.data)) {
+		return nil, 0, errors.New("value out of bounds")
+	}
+	raw := d.data[offset : offset+size]
+	next := offset + size
+	switch kind {
+	case mmdbString:
+		return string(raw), next, nil
+	case mmdbBytes:
+		return raw, next, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, errors.New("invalid double size")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), next, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, errors.New("invalid float size")
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(raw)), next, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64, mmdbInt32:
+		var value uint64
+		for _, b := range raw {
+			value = value<<8 | uint64(b)
+		}
+		if kind == mmdbInt32 {
+			return int64(int32(value)), next, nil
+		}
+		return value, next, nil
+	case mmdbUint128:
+		return raw, next, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported data type %d", kind)
+}
+
+// control decodes the control byte at the offset and returns the type
+// and size of the value along with the offset of its payload
+func (d *mmdbDecoder) control(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.data)) {
+		return 0, 0, 0, errors.New("offset out of bounds")
+	}
+	control := d.data[offset]
+	offset++
+
+	kind := int(control >> 5)
+	if kind == mmdbPointer {
+		return kind, uint(control & 0x1f), offset, nil
+	}
+	if kind == mmdbExtended {
+		if offset >= uint(len(d.data)) {
+			return 0, 0, 0, errors.New("offset out of bounds")
+		}
+		kind = 7 + int(d.data[offset])
+		offset++
+	}
+
+	size := uint(control & 0x1f)
+	if size >= 29 {
+		length := size - 28
+		if offset+length > uint(len(d.data)) {
+			return 0, 0, 0, errors.New("size out of bounds")
+		}
+		var extra uint
+		for _, b := range d.data[offset : offset+length] {
+			extra = extra<<8 | uint(b)
+		}
+		offset += length
+		switch size {
+		case 29:
+			size = 29 + extra
+		case 30:
+			size = 285 + extra
+		default:
+			size = 65821 + extra
+		}
+	}
+	return kind, size, offset, nil
+}
+
+// pointer decodes a pointer from the size bits of its control byte and the bytes following it
+func (d *mmdbDecoder) pointer(size, offset uint) (uint, uint, error) {
+	length := (size >> 3 & 0x3) + 1
+	if offset+length > uint(len(d.data)) {
+		return 0, 0, errors.New("pointer out of bounds")
+	}
+
+	var pointer uint
+	if length != 4 {
+		pointer = size & 0x7
+	}
+	for _, b := range d.data[offset : offset+length] {
+		pointer = pointer<<8 | uint(b)
+	}
+	switch length {
+	case 2:
+		pointer += 2048
+	case 3:
+		pointer += 526336
+	}
+	return pointer, offset + length, nil
+}
+
+// toUint converts a decoded unsigned integer, zero if it is not one
+func toUint(value any) uint64 {
+	number, _ := value.(uint64)
+	return number
+}
@@ -0,0 +1,80 @@
+package asn
+
+import (
+	"sort"
+	"sync"
+)
+
+// SummaryEntry contains the hosts and IP addresses found in an autonomous system
+type SummaryEntry struct {
+	Number  uint32
+	Name    string
+	Country string
+	Hosts   int
+	IPs     int
+}
+
+// Summary groups the hosts found by autonomous system. It is safe for concurrent use.
+type Summary struct {
+	mutex   sync.Mutex
+	entries map[uint32]*summaryEntry
+}
+
+type summaryEntry struct {
+	info  Info
+	hosts map[string]struct{}
+	ips   map[string]struct{}
+}
+
+// NewSummary creates an empty summary
+func NewSummary() *Summary {
+	return &Summary{entries: make(map[uint32]*summaryEntry)}
+}
+
+// Add records the host in the autonomous systems of its IP addresses
+func (s *Summary) Add(host string, infos []Info) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, info := range infos {
+		entry, ok := s.entries[info.Number]
+		if !ok {
+			entry = &summaryEntry{info: info, hosts: make(map[string]struct{}), ips: make(map[string]struct{})}
+			s.entries[info.Number] = entry
+		}
+		entry.hosts[host] = struct{}{}
+		entry.ips[info.IP] = struct{}{}
+	}
+}
+
+// Entries returns the autonomous systems recorded, the ones with the most hosts first
+func (s *Summary) Entries() []SummaryEntry {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries := make([]SummaryEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, SummaryEntry{
+			Number:  entry.info.Number,
+			Name:    entry.info.Name,
+			Country: entry.info.Country,
+			Hosts:   len(entry.hosts),
+			IPs:     len(entry.ips),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Hosts != entries[j].Hosts {
+			return entries[i].Hosts > entries[j].Hosts
+		}
+		return entries[i].Number < entries[j].Number
+	})
+	return entries
+}
@@ -0,0 +1,68 @@
+package asn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// parseTSV parses the ranges of an iptoasn TSV file. The addresses are either
+// given as IP addresses or, in the u32 variant, as 32-bit integers.
+// The ranges which are not routed (AS number 0) are left out.
+func parseTSV(reader io.Reader) ([]ipRange, error) {
+	// The AS details are shared by all the ranges of an autonomous system
+	infos := make(map[Info]*Info)
+
+	var ranges []ipRange
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("line %d: expected 5 fields, got %d", line, len(fields))
+		}
+		start, err := parseTSVAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		end, err := parseTSVAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		number, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid AS number %s", line, fields[2])
+		}
+		if number == 0 {
+			continue
+		}
+
+		info := Info{Number: uint32(number), Country: fields[3], Name: fields[4]}
+		shared, ok := infos[info]
+		if !ok {
+			shared = &info
+			infos[info] = shared
+		}
+		ranges = append(ranges, ipRange{start: start, end: end, info: shared})
+	}
+	return ranges, scanner.Err()
+}
+
+// parseTSVAddr parses an address given either as an IP address or as a 32-bit integer
+func parseTSVAddr(value string) (netip.Addr, error) {
+	if number, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return netip.AddrFrom4([4]byte{byte(number >> 24), byte(number >> 16), byte(number >> 8), byte(number)}), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid address %s", value)
+	}
+	return addr.Unmap(), nil
+}
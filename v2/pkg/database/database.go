@@ -0,0 +1,114 @@
+package database
+
+import (
+	"sort"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.etcd.io/bbolt"
+)
+
+// openTimeout is the time to wait for another process to release the database
+const openTimeout = 5 * time.Second
+
+// Record is the stored state of a subdomain
+type Record struct {
+	Host      string    `json:"host"`
+	Input     string    `json:"input"`
+	Sources   []string  `json:"sources"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// DB is a persistent store of the subdomains found by the
+// previous runs. Each input domain is stored in its own bucket.
+type DB struct {
+	db *bbolt.DB
+}
+
+// Open opens the database at the given path, creating it if needed
+func Open(path string) (*DB, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Get returns the record of a host found for the input domain
+func (d *DB) Get(input, host string) (*Record, bool, error) {
+	var record *Record
+	err := d.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(input))
+		if bucket == nil {
+			return nil
+		}
+		value := bucket.Get([]byte(host))
+		if value == nil {
+			return nil
+		}
+		record = &Record{}
+		return jsoniter.Unmarshal(value, record)
+	})
+	return record, record != nil, err
+}
+
+// IsKnown returns true if the host was found for the input domain by a previous run
+func (d *DB) IsKnown(input, host string) (bool, error) {
+	_, ok, err := d.Get(input, host)
+	return ok, err
+}
+
+// Save records the hosts found for the input domain along with their sources
+// in a single transaction. New hosts are first seen at the given time, while
+// already known hosts get their last seen time updated and sources merged.
+func (d *DB) Save(input string, sourceMap map[string]map[string]struct{}, seen time.Time) error {
+	return d.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(input))
+		if err != nil {
+			return err
+		}
+
+		for host, sources := range sourceMap {
+			record := &Record{Host: host, Input: input, FirstSeen: seen}
+			if value := bucket.Get([]byte(host)); value != nil {
+				if err := jsoniter.Unmarshal(value, record); err != nil {
+					return err
+				}
+			}
+			record.LastSeen = seen
+			record.Sources = mergeSources(record.Sources, sources)
+
+			value, err := jsoniter.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(host), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func mergeSources(existing []string, sources map[string]struct{}) []string {
+	merged := make(map[string]struct{}, len(existing)+len(sources))
+	for _, source := range existing {
+		merged[source] = struct{}{}
+	}
+	for source := range sources {
+		merged[source] = struct{}{}
+	}
+
+	result := make([]string, 0, len(merged))
+	for source := range merged {
+		result = append(result, source)
+	}
+	sort.Strings(result)
+	return result
+}
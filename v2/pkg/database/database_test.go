@@ -0,0 +1,48 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSave(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	require.Nil(t, err)
+	defer db.Close()
+
+	firstRun := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = db.Save("example.com", map[string]map[string]struct{}{
+		"www.example.com": {"crtsh": {}},
+	}, firstRun)
+	require.Nil(t, err)
+
+	known, err := db.IsKnown("example.com", "api.example.com")
+	require.Nil(t, err)
+	require.False(t, known)
+
+	secondRun := firstRun.Add(24 * time.Hour)
+	err = db.Save("example.com", map[string]map[string]struct{}{
+		"www.example.com": {"hunter": {}},
+		"api.example.com": {"crtsh": {}},
+	}, secondRun)
+	require.Nil(t, err)
+
+	record, ok, err := db.Get("example.com", "www.example.com")
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{"crtsh", "hunter"}, record.Sources)
+	require.True(t, firstRun.Equal(record.FirstSeen))
+	require.True(t, secondRun.Equal(record.LastSeen))
+
+	record, ok, err = db.Get("example.com", "api.example.com")
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.True(t, secondRun.Equal(record.FirstSeen))
+
+	known, err = db.IsKnown("other.com", "www.example.com")
+	require.Nil(t, err)
+	require.False(t, known)
+}
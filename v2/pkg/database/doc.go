@@ -0,0 +1,3 @@
+// Package database stores the enumerated subdomains across runs
+// to keep track of when each subdomain was first and last seen.
+package database
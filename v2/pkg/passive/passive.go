@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +14,14 @@ import (
 
 type EnumerationOptions struct {
 	customRateLimiter *subscraping.CustomRateLimit
+	multiRateLimiter  *ratelimit.MultiLimiter
+	statistics        *subscraping.StatisticsCollector
+	pageLimits        map[string]subscraping.PageLimits
+	retryPolicy       *subscraping.RetryPolicy
+	retryPolicies     map[string]subscraping.RetryPolicy
+	excludedSources   map[string]struct{}
+	onSourceCompleted func(source string)
+	recursiveOnly     bool
 }
 
 type EnumerateOption func(opts *EnumerationOptions)
@@ -25,6 +32,69 @@ func WithCustomRateLimit(crl *subscraping.CustomRateLimit) EnumerateOption {
 	}
 }
 
+// WithMultiRateLimiter makes the enumeration share an existing rate limiter,
+// so that the limits hold across several domains enumerated concurrently.
+// The caller owns the limiter and is responsible for stopping it.
+func WithMultiRateLimiter(mrl *ratelimit.MultiLimiter) EnumerateOption {
+	return func(opts *EnumerationOptions) {
+		opts.multiRateLimiter = mrl
+	}
+}
+
+// WithStatistics collects the statistics of the sources for the enumeration
+// into the given collector instead of a collector private to the session.
+func WithStatistics(collector *subscraping.StatisticsCollector) EnumerateOption {
+	return func(opts *EnumerationOptions) {
+		opts.statistics = collector
+	}
+}
+
+// WithPageLimits caps the pages requested from the paged sources
+// and the results read from them, by source name
+func WithPageLimits(limits map[string]subscraping.PageLimits) EnumerateOption {
+	return func(opts *EnumerationOptions) {
+		opts.pageLimits = limits
+	}
+}
+
+// WithRetryPolicy sets how the failed requests of the sources are sent again,
+// the policies given by source name replacing the default one
+func WithRetryPolicy(policy subscraping.RetryPolicy, sourcePolicies map[string]subscraping.RetryPolicy) EnumerateOption {
+	return func(opts *EnumerationOptions) {
+		opts.retryPolicy = &policy
+		opts.retryPolicies = sourcePolicies
+	}
+}
+
+// WithExcludedSources leaves the given sources of the agent out of the enumeration,
+// e.g. because they already completed for the domain in a previous run.
+func WithExcludedSources(sources ...string) EnumerateOption {
+	return func(opts *EnumerationOptions) {
+		if opts.excludedSources == nil {
+			opts.excludedSources = make(map[string]struct{}, len(sources))
+		}
+		for _, source := range sources {
+			opts.excludedSources[source] = struct{}{}
+		}
+	}
+}
+
+// WithSourceCompleted calls the callback once a source has sent all its results,
+// unless the source was interrupted by the cancellation of the enumeration
+func WithSourceCompleted(callback func(source string)) EnumerateOption {
+	return func(opts *EnumerationOptions) {
+		opts.onSourceCompleted = callback
+	}
+}
+
+// WithRecursiveSourcesOnly leaves the sources of the agent which cannot
+// handle subdomains recursively out of the enumeration
+func WithRecursiveSourcesOnly() EnumerateOption {
+	return func(opts *EnumerationOptions) {
+		opts.recursiveOnly = true
+	}
+}
+
 // EnumerateSubdomains wraps EnumerateSubdomainsWithCtx with an empty context
 func (a *Agent) EnumerateSubdomains(domain string, proxy string, rateLimit int, timeout int, maxEnumTime time.Duration, RespFileDirectory string, options ...EnumerateOption) chan subscraping.Result {
 	return a.EnumerateSubdomainsWithCtx(context.Background(), domain, proxy, rateLimit, timeout, maxEnumTime, RespFileDirectory, options...)
@@ -42,12 +112,17 @@ func (a *Agent) EnumerateSubdomainsWithCtx(ctx context.Context, domain string, p
 			enumerateOption(&enumerateOptions)
 		}
 
-		multiRateLimiter, err := a.buildMultiRateLimiter(ctx, rateLimit, enumerateOptions.customRateLimiter)
-		if err != nil {
-			results <- subscraping.Result{
-				Type: subscraping.Error, Error: fmt.Errorf("could not init multi rate limiter for %s: %s", domain, err),
+		multiRateLimiter := enumerateOptions.multiRateLimiter
+		if multiRateLimiter == nil {
+			var err error
+			multiRateLimiter, err = a.NewMultiRateLimiter(ctx, rateLimit, enumerateOptions.customRateLimiter)
+			if err != nil {
+				results <- subscraping.Result{
+					Type: subscraping.Error, Error: fmt.Errorf("could not init multi rate limiter for %s: %s", domain, err),
+				}
+				return
 			}
-			return
+			defer multiRateLimiter.Stop()
 		}
 		session, err := subscraping.NewSession(domain, proxy, multiRateLimiter, timeout, RespFileDirectory)
 		if err != nil {
@@ -57,18 +132,45 @@ func (a *Agent) EnumerateSubdomainsWithCtx(ctx context.Context, domain string, p
 			return
 		}
 		defer session.Close()
+		if enumerateOptions.statistics != nil {
+			session.Statistics = enumerateOptions.statistics
+		}
+		session.PageLimits = enumerateOptions.pageLimits
+		if enumerateOptions.retryPolicy != nil {
+			session.RetryPolicy = *enumerateOptions.retryPolicy
+			session.RetryPolicies = enumerateOptions.retryPolicies
+		}
 
 		ctx, cancel := context.WithTimeout(ctx, maxEnumTime)
 
 		wg := &sync.WaitGroup{}
 		// Run each source in parallel on the target domain
 		for _, runner := range a.sources {
+			if _, ok := enumerateOptions.excludedSources[runner.Name()]; ok {
+				continue
+			}
+			if enumerateOptions.recursiveOnly && !runner.HasRecursiveSupport() {
+				continue
+			}
 			wg.Add(1)
 			go func(source subscraping.Source) {
+				startTime := time.Now()
+				session.Statistics.AddSource(source.Name())
 				ctxWithValue := context.WithValue(ctx, subscraping.CtxSourceArg, source.Name())
 				for resp := range source.Run(ctxWithValue, domain, session) {
+					switch resp.Type {
+					case subscraping.Subdomain:
+						session.Statistics.AddResult(source.Name())
+					case subscraping.Error:
+						session.Statistics.AddError(source.Name())
+					}
 					results <- resp
 				}
+				session.Statistics.AddTimeTaken(source.Name(), time.Since(startTime))
+				// A source cut off by the cancellation or by the maximum enumeration time did not complete
+				if enumerateOptions.onSourceCompleted != nil && ctxWithValue.Err() == nil {
+					enumerateOptions.onSourceCompleted(source.Name())
+				}
 				wg.Done()
 			}(runner)
 		}
@@ -78,17 +180,18 @@ func (a *Agent) EnumerateSubdomainsWithCtx(ctx context.Context, domain string, p
 	return results
 }
 
-func (a *Agent) buildMultiRateLimiter(ctx context.Context, globalRateLimit int, rateLimit *subscraping.CustomRateLimit) (*ratelimit.MultiLimiter, error) {
+// NewMultiRateLimiter creates a rate limiter holding one limit per source of the agent
+func (a *Agent) NewMultiRateLimiter(ctx context.Context, globalRateLimit int, rateLimit *subscraping.CustomRateLimit) (*ratelimit.MultiLimiter, error) {
 	var multiRateLimiter *ratelimit.MultiLimiter
 	var err error
 	for _, source := range a.sources {
-		var rl uint
+		var rl subscraping.RateLimit
 		if sourceRateLimit, ok := rateLimit.Custom.Get(strings.ToLower(source.Name())); ok {
 			rl = sourceRateLimitOrDefault(uint(globalRateLimit), sourceRateLimit)
 		}
 
-		if rl > 0 {
-			multiRateLimiter, err = addRateLimiter(ctx, multiRateLimiter, source.Name(), rl, time.Second)
+		if rl.MaxCount > 0 {
+			multiRateLimiter, err = addRateLimiter(ctx, multiRateLimiter, source.Name(), rl.MaxCount, rl.Duration)
 		} else {
 			multiRateLimiter, err = addRateLimiter(ctx, multiRateLimiter, source.Name(), math.MaxUint32, time.Millisecond)
 		}
@@ -100,14 +203,24 @@ func (a *Agent) buildMultiRateLimiter(ctx context.Context, globalRateLimit int,
 	return multiRateLimiter, err
 }
 
-func sourceRateLimitOrDefault(defaultRateLimit uint, sourceRateLimit uint) uint {
-	if sourceRateLimit > 0 {
+func sourceRateLimitOrDefault(defaultRateLimit uint, sourceRateLimit subscraping.RateLimit) subscraping.RateLimit {
+	if sourceRateLimit.MaxCount > 0 {
+		if sourceRateLimit.Duration <= 0 {
+			sourceRateLimit.Duration = time.Second
+		}
 		return sourceRateLimit
 	}
-	return defaultRateLimit
+	return subscraping.RateLimit{MaxCount: defaultRateLimit, Duration: time.Second}
 }
 
+// addRateLimiter adds the limit of the source to the rate limiter. The limits spanning more than
+// a second are spread evenly over their duration, so that a source limited to 12 requests per
+// minute sends one every 5 seconds instead of sending 12 at once and then waiting a minute.
 func addRateLimiter(ctx context.Context, multiRateLimiter *ratelimit.MultiLimiter, key string, maxCount uint, duration time.Duration) (*ratelimit.MultiLimiter, error) {
+	if maxCount > 1 && maxCount != math.MaxUint32 && duration > time.Second && duration/time.Duration(maxCount) > 0 {
+		duration /= time.Duration(maxCount)
+		maxCount = 1
+	}
 	if multiRateLimiter == nil {
 		mrl, err := ratelimit.NewMultiLimiter(ctx, &ratelimit.Options{
 			Key:         key,
@@ -125,15 +238,3 @@ func addRateLimiter(ctx context.Context, multiRateLimiter *ratelimit.MultiLimite
 	})
 	return multiRateLimiter, err
 }
-
-func (a *Agent) GetStatistics() map[string]subscraping.Statistics {
-	stats := make(map[string]subscraping.Statistics)
-	sort.Slice(a.sources, func(i, j int) bool {
-		return a.sources[i].Name() > a.sources[j].Name()
-	})
-
-	for _, source := range a.sources {
-		stats[source.Name()] = source.Statistics()
-	}
-	return stats
-}
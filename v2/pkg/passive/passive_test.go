@@ -0,0 +1,74 @@
+package passive
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	mapsutil "github.com/projectdiscovery/utils/maps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+func TestNewMultiRateLimiter(t *testing.T) {
+	agent := New([]string{"hunter", "crtsh"}, nil, false, false)
+	rateLimit := &subscraping.CustomRateLimit{
+		Custom: mapsutil.SyncLockMap[string, subscraping.RateLimit]{
+			Map: map[string]subscraping.RateLimit{"hunter": {MaxCount: 12, Duration: time.Minute}},
+		},
+	}
+
+	multiRateLimiter, err := agent.NewMultiRateLimiter(context.Background(), 0, rateLimit)
+	require.Nil(t, err)
+	defer multiRateLimiter.Stop()
+
+	// The sources without a limit are not held back
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		require.Nil(t, multiRateLimiter.Take("crtsh"))
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 12 requests per minute are sent 5 seconds apart
+	start = time.Now()
+	require.Nil(t, multiRateLimiter.Take("hunter"))
+	assert.Less(t, time.Since(start), time.Second)
+	require.Nil(t, multiRateLimiter.Take("hunter"))
+	assert.InDelta(t, 5*time.Second, time.Since(start), float64(time.Second))
+}
+
+// blockingSource is a source which only returns once its context is done
+type blockingSource struct {
+	customSource
+}
+
+func (s *blockingSource) Run(ctx context.Context, _ string, _ *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	go func() {
+		<-ctx.Done()
+		close(results)
+	}()
+	return results
+}
+
+func (s *blockingSource) Name() string {
+	return "blockingsource"
+}
+
+func TestSourceCompletedOnTimeout(t *testing.T) {
+	agent := &Agent{sources: []subscraping.Source{&customSource{}, &blockingSource{}}}
+	rateLimit := &subscraping.CustomRateLimit{Custom: mapsutil.SyncLockMap[string, subscraping.RateLimit]{Map: map[string]subscraping.RateLimit{}}}
+	var mutex sync.Mutex
+	var completed []string
+	onCompleted := WithSourceCompleted(func(source string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		completed = append(completed, source)
+	})
+	for range agent.EnumerateSubdomains("example.com", "", 0, 5, 100*time.Millisecond, "", WithCustomRateLimit(rateLimit), onCompleted) {
+	}
+	assert.Equal(t, []string{"customsource"}, completed)
+}
@@ -1,9 +1,13 @@
 package passive
 
 import (
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping/sources/alienvault"
@@ -51,62 +55,155 @@ import (
 	mapsutil "github.com/projectdiscovery/utils/maps"
 )
 
-var AllSources = [...]subscraping.Source{
-	&alienvault.Source{},
-	&anubis.Source{},
-	&bevigil.Source{},
-	&binaryedge.Source{},
-	&bufferover.Source{},
-	&c99.Source{},
-	&censys.Source{},
-	&certspotter.Source{},
-	&chaos.Source{},
-	&chinaz.Source{},
-	&commoncrawl.Source{},
-	&crtsh.Source{},
-	&digitorus.Source{},
-	&dnsdb.Source{},
-	&dnsdumpster.Source{},
-	&dnsrepo.Source{},
-	&fofa.Source{},
-	&fullhunt.Source{},
-	&github.Source{},
-	&hackertarget.Source{},
-	&hunter.Source{},
-	&intelx.Source{},
-	&netlas.Source{},
-	&leakix.Source{},
-	&quake.Source{},
-	&rapiddns.Source{},
-	&redhuntlabs.Source{},
-	// &riddler.Source{}, // failing due to cloudfront protection
-	&robtex.Source{},
-	&securitytrails.Source{},
-	&shodan.Source{},
-	&sitedossier.Source{},
-	&threatbook.Source{},
-	&threatcrowd.Source{},
-	&virustotal.Source{},
-	&waybackarchive.Source{},
-	&whoisxmlapi.Source{},
-	&zoomeyeapi.Source{},
-	&facebook.Source{},
-	// &threatminer.Source{}, // failing  api
-	// &reconcloud.Source{}, // failing due to cloudflare bot protection
-	&builtwith.Source{},
-	&hudsonrock.Source{},
-	&digitalyama.Source{},
+// SourceConstructor creates a new instance of a source
+type SourceConstructor func() subscraping.Source
+
+// builtinSources contains the constructors of the sources shipped with subfinder
+var builtinSources = []SourceConstructor{
+	newSource[alienvault.Source],
+	newSource[anubis.Source],
+	newSource[bevigil.Source],
+	newSource[binaryedge.Source],
+	newSource[bufferover.Source],
+	newSource[c99.Source],
+	newSource[censys.Source],
+	newSource[certspotter.Source],
+	newSource[chaos.Source],
+	newSource[chinaz.Source],
+	newSource[commoncrawl.Source],
+	newSource[crtsh.Source],
+	newSource[digitorus.Source],
+	newSource[dnsdb.Source],
+	newSource[dnsdumpster.Source],
+	newSource[dnsrepo.Source],
+	newSource[fofa.Source],
+	newSource[fullhunt.Source],
+	newSource[github.Source],
+	newSource[hackertarget.Source],
+	newSource[hunter.Source],
+	newSource[intelx.Source],
+	newSource[netlas.Source],
+	newSource[leakix.Source],
+	newSource[quake.Source],
+	newSource[rapiddns.Source],
+	newSource[redhuntlabs.Source],
+	// newSource[riddler.Source], // failing due to cloudfront protection
+	newSource[robtex.Source],
+	newSource[securitytrails.Source],
+	newSource[shodan.Source],
+	newSource[sitedossier.Source],
+	newSource[threatbook.Source],
+	newSource[threatcrowd.Source],
+	newSource[virustotal.Source],
+	newSource[waybackarchive.Source],
+	newSource[whoisxmlapi.Source],
+	newSource[zoomeyeapi.Source],
+	newSource[facebook.Source],
+	// newSource[threatminer.Source], // failing  api
+	// newSource[reconcloud.Source], // failing due to cloudflare bot protection
+	newSource[builtwith.Source],
+	newSource[hudsonrock.Source],
+	newSource[digitalyama.Source],
+}
+
+// newSource is a SourceConstructor creating a zero value source of type T
+func newSource[T any, PT interface {
+	*T
+	subscraping.Source
+}]() subscraping.Source {
+	return PT(new(T))
 }
 
 var sourceWarnings = mapsutil.NewSyncLockMap[string, string](
 	mapsutil.WithMap(mapsutil.Map[string, string]{}))
 
-var NameSourceMap = make(map[string]subscraping.Source, len(AllSources))
+// registry holds the constructors of the available sources in registration order
+var registry = struct {
+	sync.RWMutex
+	names        []string
+	constructors map[string]SourceConstructor
+}{constructors: make(map[string]SourceConstructor)}
+
+// AllSources contains an instance of every registered source.
+//
+// Deprecated: the instances are shared by every caller, use NewSources
+// to get instances of your own or SourceNames for the names only.
+var AllSources []subscraping.Source
+
+// NameSourceMap maps the lowercase name of every registered source to the instance in AllSources.
+//
+// Deprecated: the instances are shared by every caller, use NewSources instead.
+var NameSourceMap = make(map[string]subscraping.Source)
 
 func init() {
-	for _, currentSource := range AllSources {
-		NameSourceMap[strings.ToLower(currentSource.Name())] = currentSource
+	for _, constructor := range builtinSources {
+		if err := RegisterSource(constructor); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterSource makes a source available for enumeration by its name.
+// It allows library users to add their own subscraping.Source implementations.
+// The constructor is called every time an agent is created so that each agent
+// gets its own instance of the source, with its own API keys.
+func RegisterSource(constructor SourceConstructor) error {
+	source := constructor()
+	name := strings.ToLower(source.Name())
+	if name == "" {
+		return errors.New("source name cannot be empty")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.constructors[name]; ok {
+		return fmt.Errorf("source %s is already registered", name)
 	}
+	registry.names = append(registry.names, name)
+	registry.constructors[name] = constructor
+	AllSources = append(AllSources, source)
+	NameSourceMap[name] = source
+	return nil
+}
+
+// UnregisterSource removes a source registered by its name, returning
+// false if no source is registered under the name
+func UnregisterSource(name string) bool {
+	name = strings.ToLower(name)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.constructors[name]; !ok {
+		return false
+	}
+	delete(registry.constructors, name)
+	index := slices.Index(registry.names, name)
+	registry.names = append(registry.names[:index:index], registry.names[index+1:]...)
+	AllSources = append(AllSources[:index:index], AllSources[index+1:]...)
+	delete(NameSourceMap, name)
+	return true
+}
+
+// NewSources creates a new instance of every registered source
+func NewSources() []subscraping.Source {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	sources := make([]subscraping.Source, 0, len(registry.names))
+	for _, name := range registry.names {
+		sources = append(sources, registry.constructors[name]())
+	}
+	return sources
+}
+
+// SourceNames returns the names of every registered source
+func SourceNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return append([]string(nil), registry.names...)
 }
 
 // Agent is a struct for running passive subdomain enumeration
@@ -116,30 +213,36 @@ type Agent struct {
 	sources []subscraping.Source
 }
 
-// New creates a new agent for passive subdomain discovery
+// New creates a new agent for passive subdomain discovery.
+// Every agent gets fresh instances of the selected sources.
 func New(sourceNames, excludedSourceNames []string, useAllSources, useSourcesSupportingRecurse bool) *Agent {
-	sources := make(map[string]subscraping.Source, len(AllSources))
+	allSources := NewSources()
+	nameSourceMap := make(map[string]subscraping.Source, len(allSources))
+	for _, currentSource := range allSources {
+		nameSourceMap[strings.ToLower(currentSource.Name())] = currentSource
+	}
+
+	sources := make(map[string]subscraping.Source, len(allSources))
 
 	if useAllSources {
-		maps.Copy(sources, NameSourceMap)
+		maps.Copy(sources, nameSourceMap)
 	} else {
 		if len(sourceNames) > 0 {
 			for _, source := range sourceNames {
-				if NameSourceMap[source] == nil {
+				if nameSourceMap[source] == nil {
 					gologger.Warning().Msgf("There is no source with the name: %s", source)
 				} else {
-					sources[source] = NameSourceMap[source]
+					sources[source] = nameSourceMap[source]
 				}
 			}
 		} else {
-			for _, currentSource := range AllSources {
+			for _, currentSource := range allSources {
 				if currentSource.IsDefault() {
 					sources[currentSource.Name()] = currentSource
 				}
 			}
 		}
 	}
-
 	if len(excludedSourceNames) > 0 {
 		for _, sourceName := range excludedSourceNames {
 			delete(sources, sourceName)
@@ -171,3 +274,16 @@ func New(sourceNames, excludedSourceNames []string, useAllSources, useSourcesSup
 
 	return agent
 }
+
+// AddApiKeys adds the API keys found in the provider config to the sources
+// of the agent. The keys are scoped to the agent and not shared with others.
+func (a *Agent) AddApiKeys(sourceApiKeys map[string][]string) {
+	for _, source := range a.sources {
+		sourceName := strings.ToLower(source.Name())
+		apiKeys := sourceApiKeys[sourceName]
+		if source.NeedsKey() && len(apiKeys) > 0 {
+			gologger.Debug().Msgf("API key(s) found for %s.", sourceName)
+			source.AddApiKeys(apiKeys)
+		}
+	}
+}
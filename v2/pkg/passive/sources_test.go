@@ -1,12 +1,15 @@
 package passive
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"golang.org/x/exp/maps"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
 
 var (
@@ -110,9 +113,9 @@ var (
 )
 
 func TestSourceCategorization(t *testing.T) {
-	defaultSources := make([]string, 0, len(AllSources))
-	recursiveSources := make([]string, 0, len(AllSources))
-	for _, source := range AllSources {
+	defaultSources := make([]string, 0, len(NewSources()))
+	recursiveSources := make([]string, 0, len(NewSources()))
+	for _, source := range NewSources() {
 		sourceName := source.Name()
 		if source.IsDefault() {
 			defaultSources = append(defaultSources, sourceName)
@@ -125,7 +128,7 @@ func TestSourceCategorization(t *testing.T) {
 
 	assert.ElementsMatch(t, expectedDefaultSources, defaultSources)
 	assert.ElementsMatch(t, expectedDefaultRecursiveSources, recursiveSources)
-	assert.ElementsMatch(t, expectedAllSources, maps.Keys(NameSourceMap))
+	assert.ElementsMatch(t, expectedAllSources, SourceNames())
 }
 
 // Review: not sure if this test is necessary/useful
@@ -153,13 +156,13 @@ func TestSourceFiltering(t *testing.T) {
 	}{
 		{someSources, someExclusions, false, false, len(someSources) - len(someExclusions)},
 		{someSources, someExclusions, false, true, 1},
-		{someSources, someExclusions, true, false, len(AllSources) - len(someExclusions)},
+		{someSources, someExclusions, true, false, len(NewSources()) - len(someExclusions)},
 
 		{someSources, []string{}, false, false, len(someSources)},
-		{someSources, []string{}, true, false, len(AllSources)},
+		{someSources, []string{}, true, false, len(NewSources())},
 
 		{[]string{}, []string{}, false, false, len(expectedDefaultSources)},
-		{[]string{}, []string{}, true, false, len(AllSources)},
+		{[]string{}, []string{}, true, false, len(NewSources())},
 		{[]string{}, []string{}, true, true, len(expectedDefaultRecursiveSources)},
 	}
 	for index, test := range tests {
@@ -175,3 +178,59 @@ func TestSourceFiltering(t *testing.T) {
 		})
 	}
 }
+
+type customSource struct {
+	apiKeys []string
+}
+
+func (s *customSource) Run(_ context.Context, _ string, _ *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	close(results)
+	return results
+}
+
+func (s *customSource) Name() string {
+	return "customsource"
+}
+
+func (s *customSource) IsDefault() bool {
+	return false
+}
+
+func (s *customSource) HasRecursiveSupport() bool {
+	return false
+}
+
+func (s *customSource) NeedsKey() bool {
+	return true
+}
+
+func (s *customSource) AddApiKeys(keys []string) {
+	s.apiKeys = keys
+}
+
+func TestRegisterSource(t *testing.T) {
+	require.Nil(t, RegisterSource(func() subscraping.Source { return &customSource{} }))
+	t.Cleanup(func() {
+		UnregisterSource("customsource")
+		assert.NotContains(t, NameSourceMap, "customsource")
+		assert.Len(t, AllSources, len(SourceNames()))
+	})
+	require.NotNil(t, RegisterSource(func() subscraping.Source { return &customSource{} }), "duplicate source registered")
+	assert.Contains(t, SourceNames(), "customsource")
+
+	// The deprecated views of the registry include the registered sources
+	assert.Len(t, AllSources, len(SourceNames()))
+	require.Contains(t, NameSourceMap, "customsource")
+	assert.Equal(t, "customsource", NameSourceMap["customsource"].Name())
+
+	first := New([]string{"customsource"}, nil, false, false)
+	second := New([]string{"customsource"}, nil, false, false)
+	first.AddApiKeys(map[string][]string{"customsource": {"first-key"}})
+	second.AddApiKeys(map[string][]string{"customsource": {"second-key"}})
+
+	require.Len(t, first.sources, 1)
+	require.Len(t, second.sources, 1)
+	assert.Equal(t, []string{"first-key"}, first.sources[0].(*customSource).apiKeys)
+	assert.Equal(t, []string{"second-key"}, second.sources[0].(*customSource).apiKeys)
+}
@@ -30,19 +30,19 @@ func TestSourcesWithKeys(t *testing.T) {
 
 	ctxParent := context.Background()
 	var multiRateLimiter *ratelimit.MultiLimiter
-	for _, source := range AllSources {
+	for _, source := range NewSources() {
 		if !source.NeedsKey() {
 			continue
 		}
 		multiRateLimiter, _ = addRateLimiter(ctxParent, multiRateLimiter, source.Name(), math.MaxInt32, time.Millisecond)
 	}
 
-	session, err := subscraping.NewSession(domain, "", multiRateLimiter, timeout)
+	session, err := subscraping.NewSession(domain, "", multiRateLimiter, timeout, "")
 	assert.Nil(t, err)
 
 	var expected = subscraping.Result{Type: subscraping.Subdomain, Value: domain, Error: nil}
 
-	for _, source := range AllSources {
+	for _, source := range NewSources() {
 		if !source.NeedsKey() {
 			continue
 		}
@@ -42,19 +42,19 @@ func TestSourcesWithoutKeys(t *testing.T) {
 	ctxParent := context.Background()
 
 	var multiRateLimiter *ratelimit.MultiLimiter
-	for _, source := range AllSources {
+	for _, source := range NewSources() {
 		if source.NeedsKey() || slices.Contains(ignoredSources, source.Name()) {
 			continue
 		}
 		multiRateLimiter, _ = addRateLimiter(ctxParent, multiRateLimiter, source.Name(), math.MaxInt32, time.Millisecond)
 	}
 
-	session, err := subscraping.NewSession(domain, "", multiRateLimiter, timeout)
+	session, err := subscraping.NewSession(domain, "", multiRateLimiter, timeout, "")
 	assert.Nil(t, err)
 
 	var expected = subscraping.Result{Type: subscraping.Subdomain, Value: domain, Error: nil}
 
-	for _, source := range AllSources {
+	for _, source := range NewSources() {
 		if source.NeedsKey() || slices.Contains(ignoredSources, source.Name()) {
 			continue
 		}
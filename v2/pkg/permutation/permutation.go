@@ -0,0 +1,136 @@
+// Package permutation generates alterations of known subdomains
+// which are likely to exist as well.
+package permutation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultWords contains the words used to alter subdomains when no word list is given
+var DefaultWords = []string{
+	"admin", "api", "app", "beta", "demo", "dev", "int", "internal", "mail", "new",
+	"old", "preprod", "prod", "qa", "sandbox", "stage", "staging", "stg", "test", "uat",
+	"v1", "v2", "vpn", "web",
+}
+
+// Generate returns the permutations of the hosts found under the domain,
+// leaving out the hosts themselves. Numeric increments come first, then
+// word swaps, dash joins and finally word insertions as new labels.
+// At most limit candidates are returned.
+func Generate(domain string, hosts, words []string, limit int) []string {
+	known := make(map[string]struct{}, len(hosts))
+	var subdomains [][]string
+	for _, host := range hosts {
+		known[host] = struct{}{}
+		if subdomain, ok := strings.CutSuffix(host, "."+domain); ok {
+			subdomains = append(subdomains, strings.Split(subdomain, "."))
+		}
+	}
+
+	generator := &generator{domain: domain, known: known, seen: make(map[string]struct{}), limit: limit}
+	alterations := []func(labels []string){
+		generator.increments,
+		func(labels []string) { generator.swaps(labels, words) },
+		func(labels []string) { generator.dashJoins(labels, words) },
+		func(labels []string) { generator.insertions(labels, words) },
+	}
+	for _, alteration := range alterations {
+		for _, labels := range subdomains {
+			if generator.full() {
+				return generator.candidates
+			}
+			alteration(labels)
+		}
+	}
+	return generator.candidates
+}
+
+type generator struct {
+	domain     string
+	known      map[string]struct{}
+	seen       map[string]struct{}
+	limit      int
+	candidates []string
+}
+
+func (g *generator) full() bool {
+	return len(g.candidates) >= g.limit
+}
+
+// add records the candidate made of the labels, unless it is known or already generated
+func (g *generator) add(labels ...string) {
+	if g.full() {
+		return
+	}
+	candidate := strings.Join(labels, ".") + "." + g.domain
+	if _, ok := g.known[candidate]; ok {
+		return
+	}
+	if _, ok := g.seen[candidate]; ok {
+		return
+	}
+	g.seen[candidate] = struct{}{}
+	g.candidates = append(g.candidates, candidate)
+}
+
+// replaced returns a copy of the labels with the label at index replaced
+func replaced(labels []string, index int, label string) []string {
+	result := append([]string{}, labels...)
+	result[index] = label
+	return result
+}
+
+// increments decrements and increments the last number of every label, e.g. app1 to app0 and app2
+func (g *generator) increments(labels []string) {
+	for i, label := range labels {
+		end := strings.LastIndexFunc(label, isDigit) + 1
+		if end == 0 {
+			continue
+		}
+		start := strings.LastIndexFunc(label[:end], func(r rune) bool { return !isDigit(r) }) + 1
+		number, err := strconv.Atoi(label[start:end])
+		if err != nil {
+			continue
+		}
+		width := end - start
+		for _, next := range []int{number - 1, number + 1} {
+			if next < 0 {
+				continue
+			}
+			g.add(replaced(labels, i, fmt.Sprintf("%s%0*d%s", label[:start], width, next, label[end:]))...)
+		}
+	}
+}
+
+// swaps replaces every label with every word, e.g. dev.api to stg.api
+func (g *generator) swaps(labels, words []string) {
+	for i := range labels {
+		for _, word := range words {
+			g.add(replaced(labels, i, word)...)
+		}
+	}
+}
+
+// dashJoins joins every word to the first label with a dash, e.g. api to api-dev and dev-api
+func (g *generator) dashJoins(labels, words []string) {
+	for _, word := range words {
+		g.add(replaced(labels, 0, labels[0]+"-"+word)...)
+		g.add(replaced(labels, 0, word+"-"+labels[0])...)
+	}
+}
+
+// insertions inserts every word as a new label at every position, e.g. api to dev.api and api.dev
+func (g *generator) insertions(labels, words []string) {
+	for _, word := range words {
+		for i := 0; i <= len(labels); i++ {
+			inserted := append(append(append([]string{}, labels[:i]...), word), labels[i:]...)
+			g.add(inserted...)
+		}
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
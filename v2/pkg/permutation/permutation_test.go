@@ -0,0 +1,30 @@
+package permutation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	candidates := Generate("example.com", []string{"app01.example.com", "dev.example.com", "example.org"}, []string{"dev"}, 100)
+	assert.Equal(t, []string{
+		// increments
+		"app00.example.com",
+		"app02.example.com",
+		// swaps, dev.example.com is already known
+		// dash joins
+		"app01-dev.example.com",
+		"dev-app01.example.com",
+		"dev-dev.example.com",
+		// insertions
+		"dev.app01.example.com",
+		"app01.dev.example.com",
+		"dev.dev.example.com",
+	}, candidates)
+}
+
+func TestGenerateLimit(t *testing.T) {
+	candidates := Generate("example.com", []string{"app1.example.com"}, DefaultWords, 3)
+	assert.Equal(t, []string{"app0.example.com", "app2.example.com", "admin.example.com"}, candidates)
+}
@@ -0,0 +1,86 @@
+// Package probe requests the hosts found over HTTP and HTTPS to report
+// what they serve.
+package probe
+
+import (
+	"context"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/corpix/uarand"
+)
+
+// maxBodySize is the number of bytes of a response body read to find its title
+const maxBodySize = 1 << 20
+
+var titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Response contains the details of the response of a host to a probe request
+type Response struct {
+	URL           string
+	StatusCode    int
+	Title         string
+	ContentLength int64
+	// FinalURL is the URL the redirects of the request ended at
+	FinalURL string
+	Server   string
+}
+
+// Host requests the root of the host over HTTP and HTTPS with the client,
+// following redirects, and returns the responses received
+func Host(ctx context.Context, client *http.Client, host string) []Response {
+	var responses []Response
+	for _, scheme := range []string{"http", "https"} {
+		response, err := request(ctx, client, scheme+"://"+host)
+		if err != nil {
+			continue
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// request requests the URL and reads the details of the final response
+func request(ctx context.Context, client *http.Client, requestURL string) (Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return Response{}, err
+	}
+	req.Header.Set("User-Agent", uarand.GetRandom())
+	req.Header.Set("Accept", "*/*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return Response{}, err
+	}
+	response := Response{
+		URL:           requestURL,
+		StatusCode:    resp.StatusCode,
+		Title:         Title(body),
+		ContentLength: resp.ContentLength,
+		FinalURL:      resp.Request.URL.String(),
+		Server:        resp.Header.Get("Server"),
+	}
+	if response.ContentLength < 0 {
+		response.ContentLength = int64(len(body))
+	}
+	return response, nil
+}
+
+// Title returns the title of the HTML page, with its whitespace collapsed
+func Title(body []byte) string {
+	match := titleRegex.FindSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")
+}
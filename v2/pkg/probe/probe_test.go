@@ -0,0 +1,42 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost(t *testing.T) {
+	const body = "<html><head><TITLE>\n  Sign in &amp; continue\n</TITLE></head></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/" {
+			http.Redirect(w, req, "/login", http.StatusFound)
+			return
+		}
+		w.Header().Set("Server", "nginx")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	responses := Host(context.Background(), server.Client(), host)
+	require.Len(t, responses, 1)
+	assert.Equal(t, Response{
+		URL:           server.URL,
+		StatusCode:    http.StatusOK,
+		Title:         "Sign in & continue",
+		ContentLength: int64(len(body)),
+		FinalURL:      server.URL + "/login",
+		Server:        "nginx",
+	}, responses[0])
+}
+
+func TestTitle(t *testing.T) {
+	assert.Equal(t, "Example", Title([]byte(`<title lang="en">Example</title>`)))
+	assert.Equal(t, "", Title([]byte("<html></html>")))
+}
@@ -1,7 +1,15 @@
 package resolve
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
 	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/projectdiscovery/retryabledns"
 )
 
 // DefaultResolvers contains the default list of resolvers known to be good
@@ -18,15 +26,143 @@ var DefaultResolvers = []string{
 	"208.67.220.220:53", // OpenDNS Secondary
 }
 
+// DefaultRecordTypes contains the record types always queried for a host
+var DefaultRecordTypes = []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeCNAME}
+
+// OptionalRecordTypes contains the record types which can be queried in addition to the default ones
+var OptionalRecordTypes = map[string]uint16{
+	"mx":  dns.TypeMX,
+	"ns":  dns.TypeNS,
+	"txt": dns.TypeTXT,
+}
+
+// RecordTypes returns the default record types along with the optional record types given by name
+func RecordTypes(optional []string) ([]uint16, error) {
+	recordTypes := append([]uint16{}, DefaultRecordTypes...)
+	for _, name := range optional {
+		recordType, ok := OptionalRecordTypes[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid record type %s", name)
+		}
+		recordTypes = append(recordTypes, recordType)
+	}
+	return recordTypes, nil
+}
+
 // Resolver is a struct for resolving DNS names
 type Resolver struct {
 	DNSClient *dnsx.DNSX
 	Resolvers []string
+	// Proxy is the proxy the DoH resolvers are queried through, if any.
+	// It must be set before the resolvers.
+	Proxy *url.URL
+	// HealthCheckName is the name queried by the health checks, expected
+	// to resolve to one of HealthCheckIPs
+	HealthCheckName string
+	HealthCheckIPs  []string
+
+	// mutex guards the client and resolvers replaced by the health checks
+	mutex sync.RWMutex
+	// configured contains every resolver set, the pruned ones being checked again
+	configured []string
+	statistics map[string]*ResolverStatistics
+	// forwarders serve the encrypted resolvers to the DNS client
+	forwarders map[string]*forwarder
+	// typeClients holds the DNS clients querying other record types, reset with the resolvers
+	typeClients map[string]*dnsx.DNSX
 }
 
 // New creates a new resolver struct with the default resolvers
 func New() *Resolver {
 	return &Resolver{
-		Resolvers: []string{},
+		Resolvers:       []string{},
+		HealthCheckName: DefaultHealthCheckName,
+		HealthCheckIPs:  DefaultHealthCheckIPs,
+		statistics:      make(map[string]*ResolverStatistics),
+		forwarders:      make(map[string]*forwarder),
+		typeClients:     make(map[string]*dnsx.DNSX),
+	}
+}
+
+// SetResolvers creates the DNS client querying the resolvers with the options.
+// A resolver is either a plain ip:port address, a DoH url (https://host/dns-query)
+// or a DoT address (tls://host:853).
+func (r *Resolver) SetResolvers(resolvers []string, options dnsx.Options) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	dnsClient, err := r.newClient(resolvers, options)
+	if err != nil {
+		return err
+	}
+	r.DNSClient = dnsClient
+	r.Resolvers = resolvers
+	r.configured = resolvers
+	clear(r.typeClients)
+	return nil
+}
+
+// newClient creates a DNS client querying the resolvers, starting a forwarder
+// for every encrypted resolver which has none yet. The caller must hold the mutex.
+func (r *Resolver) newClient(resolvers []string, options dnsx.Options) (*dnsx.DNSX, error) {
+	options.BaseResolvers = make([]string, 0, len(resolvers))
+	for _, resolver := range resolvers {
+		if !isEncrypted(resolver) {
+			options.BaseResolvers = append(options.BaseResolvers, resolver)
+			continue
+		}
+
+		forwarder, ok := r.forwarders[resolver]
+		if !ok {
+			var err error
+			forwarder, err = startForwarder(newUpstream(resolver, upstreamTimeout, upstreamConnections, r.Proxy))
+			if err != nil {
+				return nil, err
+			}
+			r.forwarders[resolver] = forwarder
+		}
+		options.BaseResolvers = append(options.BaseResolvers, forwarder.address)
 	}
+	return dnsx.New(options)
+}
+
+// QueryTypes resolves the given record types of the name with the resolvers in use
+func (r *Resolver) QueryTypes(name string, questionTypes ...uint16) (*retryabledns.DNSData, error) {
+	r.mutex.Lock()
+	key := fmt.Sprint(questionTypes)
+	dnsClient, ok := r.typeClients[key]
+	if !ok {
+		options := *r.DNSClient.Options
+		options.QuestionTypes = questionTypes
+		var err error
+		dnsClient, err = r.newClient(r.Resolvers, options)
+		if err != nil {
+			r.mutex.Unlock()
+			return nil, err
+		}
+		r.typeClients[key] = dnsClient
+	}
+	r.mutex.Unlock()
+	return dnsClient.QueryMultiple(name)
+}
+
+// Close stops the forwarders of the encrypted resolvers
+func (r *Resolver) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var errs []error
+	for resolver, forwarder := range r.forwarders {
+		errs = append(errs, forwarder.close())
+		delete(r.forwarders, resolver)
+	}
+	return errors.Join(errs...)
+}
+
+// client returns the DNS client querying the healthy resolvers
+func (r *Resolver) client() *dnsx.DNSX {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.DNSClient
 }
@@ -0,0 +1,183 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/gologger"
+	"github.com/rs/xid"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// DefaultHealthCheckName is the name with well known stable answers queried by default
+	DefaultHealthCheckName = "dns.google"
+	// healthCheckNXDomain is the zone random names are queried under to check NXDOMAIN answers
+	healthCheckNXDomain = "example.com"
+)
+
+// DefaultHealthCheckIPs contains the addresses DefaultHealthCheckName resolves to
+var DefaultHealthCheckIPs = []string{"8.8.8.8", "8.8.4.4"}
+
+// ResolverStatistics contains the results of the health checks of a resolver
+type ResolverStatistics struct {
+	Checks  int           // Checks is the number of health checks run against the resolver
+	Errors  int           // Errors is the number of failed health checks
+	Latency time.Duration // Latency is the average latency of the health check queries
+	Healthy bool          // Healthy is false once the resolver has been pruned
+	Reason  string        // Reason is why the resolver was pruned
+}
+
+// CheckHealth checks every resolver set by querying HealthCheckName, expected
+// to resolve to one of HealthCheckIPs, and a name which does not exist. The resolvers which time out, answer
+// inconsistently or do not answer NXDOMAIN are pruned, and the pruned ones
+// which pass the check again are put back in use. The resolvers are kept as
+// they are if none of them is healthy.
+func (r *Resolver) CheckHealth(timeout time.Duration) error {
+	r.mutex.RLock()
+	resolvers := append([]string{}, r.configured...)
+	r.mutex.RUnlock()
+
+	errs := make([]error, len(resolvers))
+	latencies := make([]time.Duration, len(resolvers))
+	wg := &sync.WaitGroup{}
+	for i, resolver := range resolvers {
+		wg.Add(1)
+		go func(i int, resolver string) {
+			defer wg.Done()
+			latencies[i], errs[i] = checkResolver(resolver, r.HealthCheckName, r.HealthCheckIPs, timeout, r.Proxy)
+		}(i, resolver)
+	}
+	wg.Wait()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var healthy []string
+	for i, resolver := range resolvers {
+		stats, ok := r.statistics[resolver]
+		if !ok {
+			stats = &ResolverStatistics{Healthy: true}
+			r.statistics[resolver] = stats
+		}
+		stats.Latency = (stats.Latency*time.Duration(stats.Checks) + latencies[i]) / time.Duration(stats.Checks+1)
+		stats.Checks++
+		if errs[i] != nil {
+			stats.Errors++
+			stats.Reason = errs[i].Error()
+			continue
+		}
+		healthy = append(healthy, resolver)
+	}
+
+	if len(healthy) == 0 {
+		gologger.Warning().Msgf("No resolver passed the health check, keeping the %d resolvers in use\n", len(r.Resolvers))
+		return nil
+	}
+	if slices.Equal(healthy, r.Resolvers) {
+		return nil
+	}
+
+	dnsClient, err := r.newClient(healthy, *r.DNSClient.Options)
+	if err != nil {
+		return err
+	}
+	for i, resolver := range resolvers {
+		stats := r.statistics[resolver]
+		switch {
+		case errs[i] != nil && stats.Healthy:
+			gologger.Verbose().Msgf("Pruning resolver %s: %s\n", resolver, errs[i])
+			if forwarder, ok := r.forwarders[resolver]; ok {
+				if err := forwarder.close(); err != nil {
+					gologger.Debug().Msgf("Could not stop the forwarder of resolver %s: %s\n", resolver, err)
+				}
+				delete(r.forwarders, resolver)
+			}
+		case errs[i] == nil && !stats.Healthy:
+			gologger.Verbose().Msgf("Restoring resolver %s\n", resolver)
+			stats.Reason = ""
+		}
+		stats.Healthy = errs[i] == nil
+	}
+	r.DNSClient = dnsClient
+	r.Resolvers = healthy
+	clear(r.typeClients)
+	return nil
+}
+
+// StartHealthChecks checks the health of the resolvers periodically until the context is done
+func (r *Resolver) StartHealthChecks(ctx context.Context, interval, timeout time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.CheckHealth(timeout); err != nil {
+					gologger.Warning().Msgf("Could not check resolvers: %s\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// Statistics returns the health check results of every resolver checked
+func (r *Resolver) Statistics() map[string]ResolverStatistics {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	statistics := make(map[string]ResolverStatistics, len(r.statistics))
+	for resolver, stats := range r.statistics {
+		statistics[resolver] = *stats
+	}
+	return statistics
+}
+
+// checkResolver runs the health check queries against the resolver and returns their average latency
+func checkResolver(resolver, name string, ips []string, timeout time.Duration, proxyURL *url.URL) (time.Duration, error) {
+	upstream := newUpstream(resolver, timeout, 1, proxyURL)
+	defer closeUpstream(upstream)
+
+	msg := new(dns.Msg).SetQuestion(dns.Fqdn(name), dns.TypeA)
+	answer, knownLatency, err := exchangeTimed(upstream, msg)
+	if err != nil {
+		return timeout, err
+	}
+	if answer.Rcode != dns.RcodeSuccess || !answersWith(answer, ips) {
+		return knownLatency, fmt.Errorf("inconsistent answer for %s", name)
+	}
+
+	msg = new(dns.Msg).SetQuestion(dns.Fqdn(xid.New().String()+"."+healthCheckNXDomain), dns.TypeA)
+	answer, nxLatency, err := exchangeTimed(upstream, msg)
+	if err != nil {
+		return timeout, err
+	}
+	if answer.Rcode != dns.RcodeNameError || len(answer.Answer) > 0 {
+		return (knownLatency + nxLatency) / 2, errors.New("answer for a name which does not exist")
+	}
+	return (knownLatency + nxLatency) / 2, nil
+}
+
+// exchangeTimed sends the query to the upstream and returns the answer along with its latency
+func exchangeTimed(upstream upstream, msg *dns.Msg) (*dns.Msg, time.Duration, error) {
+	start := time.Now()
+	answer, err := upstream.exchange(msg)
+	return answer, time.Since(start), err
+}
+
+// answersWith returns true if the answer contains one of the addresses
+func answersWith(answer *dns.Msg, ips []string) bool {
+	for _, record := range answer.Answer {
+		if a, ok := record.(*dns.A); ok && slices.Contains(ips, a.A.String()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package resolve
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDNSServer starts a local DNS server answering every A query with the answer function
+func startDNSServer(t *testing.T, answer func(name string) []string) string {
+	return serveDNS(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg).SetReply(req)
+		ips := answer(req.Question[0].Name)
+		if len(ips) == 0 {
+			msg.Rcode = dns.RcodeNameError
+		}
+		for _, ip := range ips {
+			msg.Answer = append(msg.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP(ip),
+			})
+		}
+		_ = w.WriteMsg(msg)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	honest := startDNSServer(t, func(name string) []string {
+		if name == dns.Fqdn(DefaultHealthCheckName) {
+			return DefaultHealthCheckIPs
+		}
+		return nil
+	})
+	var recovered atomic.Bool
+	lying := startDNSServer(t, func(name string) []string {
+		if !recovered.Load() {
+			return []string{"192.0.2.1"}
+		}
+		if name == dns.Fqdn(DefaultHealthCheckName) {
+			return DefaultHealthCheckIPs
+		}
+		return nil
+	})
+	silent := "127.0.0.1:1"
+
+	resolver := New()
+	err := resolver.SetResolvers([]string{honest, lying, silent}, dnsx.Options{MaxRetries: 1, QuestionTypes: DefaultRecordTypes})
+	require.Nil(t, err)
+
+	require.Nil(t, resolver.CheckHealth(500*time.Millisecond))
+	assert.Equal(t, []string{honest}, resolver.Resolvers)
+	assert.Equal(t, []string{honest}, resolver.client().Options.BaseResolvers)
+
+	statistics := resolver.Statistics()
+	assert.True(t, statistics[honest].Healthy)
+	assert.False(t, statistics[lying].Healthy)
+	assert.False(t, statistics[silent].Healthy)
+	assert.Equal(t, 1, statistics[lying].Errors)
+
+	// The pruned resolvers are checked again and put back in use once healthy
+	recovered.Store(true)
+	require.Nil(t, resolver.CheckHealth(500*time.Millisecond))
+	assert.Equal(t, []string{honest, lying}, resolver.Resolvers)
+	assert.True(t, resolver.Statistics()[lying].Healthy)
+	assert.False(t, resolver.Statistics()[silent].Healthy)
+
+	// The forwarders of the encrypted resolvers pruned are stopped
+	resolver = New()
+	err = resolver.SetResolvers([]string{honest, "tls://127.0.0.1:1"}, dnsx.Options{MaxRetries: 1, QuestionTypes: DefaultRecordTypes})
+	require.Nil(t, err)
+	require.Len(t, resolver.forwarders, 1)
+	require.Nil(t, resolver.CheckHealth(500*time.Millisecond))
+	assert.Equal(t, []string{honest}, resolver.Resolvers)
+	assert.Empty(t, resolver.forwarders)
+
+	// The resolvers are kept as they are when none of them is healthy
+	resolver = New()
+	err = resolver.SetResolvers([]string{silent}, dnsx.Options{MaxRetries: 1, QuestionTypes: DefaultRecordTypes})
+	require.Nil(t, err)
+	require.Nil(t, resolver.CheckHealth(500*time.Millisecond))
+	assert.Equal(t, []string{silent}, resolver.Resolvers)
+
+	// The name checked and its expected answers are configurable
+	internal := startDNSServer(t, func(name string) []string {
+		if name == "health.corp.internal." {
+			return []string{"10.0.0.53"}
+		}
+		return nil
+	})
+	resolver = New()
+	resolver.HealthCheckName = "health.corp.internal"
+	resolver.HealthCheckIPs = []string{"10.0.0.53"}
+	err = resolver.SetResolvers([]string{honest, internal}, dnsx.Options{MaxRetries: 1, QuestionTypes: DefaultRecordTypes})
+	require.Nil(t, err)
+	require.Nil(t, resolver.CheckHealth(500*time.Millisecond))
+	assert.Equal(t, []string{internal}, resolver.Resolvers)
+	assert.Equal(t, "inconsistent answer for health.corp.internal", resolver.Statistics()[honest].Reason)
+}
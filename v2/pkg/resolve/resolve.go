@@ -1,10 +1,14 @@
 package resolve
 
 import (
-	"fmt"
 	"sync"
 
-	"github.com/rs/xid"
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/gologger"
+	"golang.org/x/exp/slices"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/asn"
+	"github.com/YouChenJun/subfinder-plus/pkg/probe"
 )
 
 const (
@@ -19,8 +23,15 @@ type ResolutionPool struct {
 	Results        chan Result
 	wg             *sync.WaitGroup
 	removeWildcard bool
-
-	wildcardIPs map[string]struct{}
+	// LabelWildcards keeps the wildcard subdomains in the results, labeled
+	// with their wildcard zone. It must be set before sending any task.
+	LabelWildcards bool
+	// KeepDangling keeps the subdomains whose CNAME chain does not lead to
+	// an address in the results. It must be set before sending any task.
+	KeepDangling bool
+	// Wildcards caches the wildcard zones probed by the pool. It can be replaced
+	// by the cache of another pool before sending any task, to share its probes.
+	Wildcards *WildcardCache
 }
 
 // HostEntry defines a host with the source
@@ -28,6 +39,10 @@ type HostEntry struct {
 	Domain string
 	Host   string
 	Source string
+	// Parent is the subdomain enumerated recursively which led to the host, if any
+	Parent string
+	// Records are the records of a host resolved beforehand, which are not queried again
+	Records *Records
 }
 
 // ResponseData contains the source and response,used for output
@@ -38,11 +53,36 @@ type ResponseData struct {
 
 // Result contains the result for a host resolution
 type Result struct {
-	Type   ResultType
-	Host   string
-	IP     string
+	Type    ResultType
+	Host    string
+	IP      string
+	Records Records
+	// Wildcard is the wildcard zone the host belongs to, if any
+	Wildcard string
+	// ASN contains the autonomous systems of the IP addresses of the host
+	ASN    []asn.Info
 	Error  error
 	Source string
+	Parent string
+	// Probe contains the responses of the host to the HTTP probe requests
+	Probe []probe.Response
+	// Takeover is the service the host could be taken over through, if any
+	Takeover string
+}
+
+// Records contains the DNS records found for a host
+type Records struct {
+	A     []string
+	AAAA  []string
+	CNAME []string // CNAME contains the CNAME chain in resolution order
+	MX    []string
+	NS    []string
+	TXT   []string
+}
+
+// IPs returns the IPv4 and IPv6 addresses of the host
+func (r Records) IPs() []string {
+	return append(append([]string{}, r.A...), r.AAAA...)
 }
 
 // ResultType is the type of result found
@@ -62,7 +102,7 @@ func (r *Resolver) NewResolutionPool(workers int, removeWildcard bool) *Resoluti
 		Results:        make(chan Result),
 		wg:             &sync.WaitGroup{},
 		removeWildcard: removeWildcard,
-		wildcardIPs:    make(map[string]struct{}),
+		Wildcards:      r.NewWildcardCache(),
 	}
 
 	go func() {
@@ -77,53 +117,82 @@ func (r *Resolver) NewResolutionPool(workers int, removeWildcard bool) *Resoluti
 	return resolutionPool
 }
 
-// InitWildcards inits the wildcard ips array
+// InitWildcards probes the domain for wildcard answers. The zones under the
+// domain are probed on demand while resolving the subdomains found in them.
 func (r *ResolutionPool) InitWildcards(domain string) error {
-	for i := 0; i < maxWildcardChecks; i++ {
-		uid := xid.New().String()
+	return r.Wildcards.zone(domain).err
+}
 
-		hosts, _ := r.DNSClient.Lookup(uid + "." + domain)
-		if len(hosts) == 0 {
-			return fmt.Errorf("%s is not a wildcard domain", domain)
+// wildcardZone returns the wildcard zone the host belongs to along with the
+// reason, checking every zone from the domain down to the parent of the host
+func (r *ResolutionPool) wildcardZone(domain, host string, records Records) (string, string) {
+	for _, zone := range parentZones(domain, host) {
+		if reason, ok := r.Wildcards.zone(zone).match(records); ok {
+			return zone, reason
 		}
+	}
+	return "", ""
+}
 
-		// Append all wildcard ips found for domains
-		for _, host := range hosts {
-			r.wildcardIPs[host] = struct{}{}
-		}
+// queries returns true if the records of the given type are queried for every host
+func (r *ResolutionPool) queries(recordType uint16) bool {
+	return slices.Contains(r.client().Options.QuestionTypes, recordType)
+}
+
+// records returns the records of the host of the task, querying them unless the task has them
+func (r *ResolutionPool) records(task HostEntry) (Records, error) {
+	if task.Records != nil {
+		return *task.Records, nil
+	}
+	data, err := r.client().QueryMultiple(task.Host)
+	if err != nil {
+		return Records{}, err
+	}
+
+	records := Records{A: data.A, AAAA: data.AAAA, CNAME: data.CNAME}
+	// The authority and additional sections may carry records which were not asked for
+	if r.queries(dns.TypeMX) {
+		records.MX = data.MX
+	}
+	if r.queries(dns.TypeNS) {
+		records.NS = data.NS
 	}
-	return nil
+	if r.queries(dns.TypeTXT) {
+		records.TXT = data.TXT
+	}
+	return records, nil
 }
 
 func (r *ResolutionPool) resolveWorker() {
 	for task := range r.Tasks {
 		if !r.removeWildcard {
-			r.Results <- Result{Type: Subdomain, Host: task.Host, IP: "", Source: task.Source}
+			r.Results <- Result{Type: Subdomain, Host: task.Host, IP: "", Source: task.Source, Parent: task.Parent}
 			continue
 		}
 
-		hosts, err := r.DNSClient.Lookup(task.Host)
+		records, err := r.records(task)
 		if err != nil {
 			r.Results <- Result{Type: Error, Host: task.Host, Source: task.Source, Error: err}
 			continue
 		}
-
-		if len(hosts) == 0 {
+		hosts := records.IPs()
+		if len(hosts) == 0 && (!r.KeepDangling || len(records.CNAME) == 0) {
 			continue
 		}
 
-		var skip bool
-		for _, host := range hosts {
-			// Ignore the host if it exists in wildcard ips map
-			if _, ok := r.wildcardIPs[host]; ok {
-				skip = true
-				break
-			}
+		result := Result{Type: Subdomain, Host: task.Host, Records: records, Source: task.Source, Parent: task.Parent}
+		if len(hosts) > 0 {
+			result.IP = hosts[0]
 		}
-
-		if !skip {
-			r.Results <- Result{Type: Subdomain, Host: task.Host, IP: hosts[0], Source: task.Source}
+		if zone, reason := r.wildcardZone(task.Domain, task.Host, records); zone != "" {
+			if !r.LabelWildcards {
+				gologger.Verbose().Msgf("Removing wildcard subdomain %s: %s matches *.%s\n", task.Host, reason, zone)
+				continue
+			}
+			gologger.Verbose().Msgf("Labeling wildcard subdomain %s: %s matches *.%s\n", task.Host, reason, zone)
+			result.Wildcard = zone
 		}
+		r.Results <- result
 	}
 	r.wg.Done()
 }
@@ -0,0 +1,109 @@
+package resolve
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveDNS starts a local DNS server replying to every query with the handler
+func serveDNS(t *testing.T, handler dns.HandlerFunc) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	server := &dns.Server{PacketConn: conn, Handler: handler}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return conn.LocalAddr().String()
+}
+
+// serveRecords answers the queries for www.example.com with a record of every type,
+// adding an NS record to the authority section whatever the type asked for
+func serveRecords(w dns.ResponseWriter, req *dns.Msg) {
+	msg := new(dns.Msg).SetReply(req)
+	question := req.Question[0]
+	if question.Name != "www.example.com." {
+		msg.Rcode = dns.RcodeNameError
+		_ = w.WriteMsg(msg)
+		return
+	}
+
+	header := func(name string, rrtype uint16) dns.RR_Header {
+		return dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET, Ttl: 60}
+	}
+	cname := &dns.CNAME{Hdr: header(question.Name, dns.TypeCNAME), Target: "lb.example.net."}
+	switch question.Qtype {
+	case dns.TypeA:
+		msg.Answer = append(msg.Answer, cname, &dns.A{Hdr: header("lb.example.net.", dns.TypeA), A: net.ParseIP("192.0.2.1")})
+	case dns.TypeAAAA:
+		msg.Answer = append(msg.Answer, cname, &dns.AAAA{Hdr: header("lb.example.net.", dns.TypeAAAA), AAAA: net.ParseIP("2001:db8::1")})
+	case dns.TypeCNAME:
+		msg.Answer = append(msg.Answer, cname)
+	case dns.TypeMX:
+		msg.Answer = append(msg.Answer, &dns.MX{Hdr: header(question.Name, dns.TypeMX), Preference: 10, Mx: "mail.example.com."})
+	case dns.TypeNS:
+		msg.Answer = append(msg.Answer, &dns.NS{Hdr: header(question.Name, dns.TypeNS), Ns: "ns1.example.com."})
+	case dns.TypeTXT:
+		msg.Answer = append(msg.Answer, &dns.TXT{Hdr: header(question.Name, dns.TypeTXT), Txt: []string{"v=spf1 -all"}})
+	}
+	msg.Ns = append(msg.Ns, &dns.NS{Hdr: header("example.com.", dns.TypeNS), Ns: "ns2.example.com."})
+	_ = w.WriteMsg(msg)
+}
+
+func TestRecordTypes(t *testing.T) {
+	recordTypes, err := RecordTypes(nil)
+	require.Nil(t, err)
+	assert.Equal(t, DefaultRecordTypes, recordTypes)
+
+	recordTypes, err = RecordTypes([]string{"MX", "txt"})
+	require.Nil(t, err)
+	assert.Equal(t, []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeCNAME, dns.TypeMX, dns.TypeTXT}, recordTypes)
+
+	_, err = RecordTypes([]string{"soa"})
+	assert.NotNil(t, err)
+}
+
+func TestResolutionPoolRecords(t *testing.T) {
+	server := serveDNS(t, serveRecords)
+
+	// resolve returns the records of www.example.com queried with the optional record types
+	resolve := func(optional ...string) Records {
+		recordTypes, err := RecordTypes(optional)
+		require.Nil(t, err)
+		resolver := New()
+		require.Nil(t, resolver.SetResolvers([]string{server}, dnsx.Options{MaxRetries: 1, QuestionTypes: recordTypes}))
+
+		resolutionPool := resolver.NewResolutionPool(1, true)
+		resolutionPool.Tasks <- HostEntry{Domain: "example.com", Host: "www.example.com", Source: "test"}
+		close(resolutionPool.Tasks)
+
+		var results []Result
+		for result := range resolutionPool.Results {
+			results = append(results, result)
+		}
+		require.Len(t, results, 1)
+		require.Equal(t, Subdomain, results[0].Type, results[0].Error)
+		assert.Equal(t, "192.0.2.1", results[0].IP)
+		return results[0].Records
+	}
+
+	// The addresses and the CNAME chain are always collected, the records of the
+	// other sections are left out unless their type was asked for
+	records := resolve()
+	assert.Equal(t, []string{"192.0.2.1"}, records.A)
+	assert.Equal(t, []string{"2001:db8::1"}, records.AAAA)
+	assert.Equal(t, []string{"lb.example.net"}, records.CNAME)
+	assert.Empty(t, records.MX)
+	assert.Empty(t, records.NS)
+	assert.Empty(t, records.TXT)
+
+	records = resolve("mx", "ns", "txt")
+	assert.Equal(t, []string{"192.0.2.1"}, records.A)
+	assert.Equal(t, []string{"mail.example.com"}, records.MX)
+	assert.ElementsMatch(t, []string{"ns1.example.com", "ns2.example.com"}, records.NS)
+	assert.Equal(t, []string{"v=spf1 -all"}, records.TXT)
+}
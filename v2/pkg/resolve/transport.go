@@ -0,0 +1,263 @@
+package resolve
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// upstreamTimeout is the timeout of the queries forwarded to the encrypted resolvers
+	upstreamTimeout = 2 * time.Second
+	// upstreamConnections is the number of idle connections kept open to every encrypted resolver
+	upstreamConnections = 64
+	// dohContentType is the media type of the DNS messages sent over HTTPS (RFC 8484)
+	dohContentType = "application/dns-message"
+)
+
+// upstream sends DNS queries to a resolver
+type upstream interface {
+	exchange(msg *dns.Msg) (*dns.Msg, error)
+}
+
+// isEncrypted returns true if the resolver is a DoH url or a DoT address
+func isEncrypted(resolver string) bool {
+	return strings.HasPrefix(resolver, "https://") || strings.HasPrefix(resolver, "tls://")
+}
+
+// newUpstream creates the upstream of a resolver, which is either a DoH url
+// (https://host/dns-query), a DoT address (tls://host:853) or a plain ip:port.
+// The DoH queries are sent through the proxy if any.
+func newUpstream(resolver string, timeout time.Duration, connections int, proxyURL *url.URL) upstream {
+	if strings.HasPrefix(resolver, "https://") {
+		proxy := http.ProxyFromEnvironment
+		if proxyURL != nil {
+			proxy = http.ProxyURL(proxyURL)
+		}
+		return &httpsUpstream{
+			url: resolver,
+			client: &http.Client{
+				Timeout: timeout,
+				Transport: &http.Transport{
+					Proxy:               proxy,
+					ForceAttemptHTTP2:   true,
+					MaxIdleConns:        connections,
+					MaxIdleConnsPerHost: connections,
+					IdleConnTimeout:     90 * time.Second,
+				},
+			},
+		}
+	}
+
+	if address, ok := strings.CutPrefix(resolver, "tls://"); ok {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, "853")
+		}
+		host, _, _ := net.SplitHostPort(address)
+		return &tlsUpstream{
+			address: address,
+			client:  &dns.Client{Net: "tcp-tls", Timeout: timeout, TLSConfig: &tls.Config{ServerName: host}},
+			idle:    make(chan *dns.Conn, connections),
+		}
+	}
+	return &plainUpstream{address: resolver, client: &dns.Client{Timeout: timeout}}
+}
+
+// plainUpstream sends the queries over UDP
+type plainUpstream struct {
+	address string
+	client  *dns.Client
+}
+
+func (u *plainUpstream) exchange(msg *dns.Msg) (*dns.Msg, error) {
+	answer, _, err := u.client.Exchange(msg, u.address)
+	return answer, err
+}
+
+// tlsUpstream sends the queries over TLS, reusing the connections between queries
+type tlsUpstream struct {
+	address string
+	client  *dns.Client
+	idle    chan *dns.Conn
+}
+
+func (u *tlsUpstream) exchange(msg *dns.Msg) (*dns.Msg, error) {
+	// An idle connection may have been closed by the server in the meantime,
+	// so the query is sent once more over a new connection if it fails
+	select {
+	case conn := <-u.idle:
+		if answer, err := u.exchangeWithConn(msg, conn); err == nil {
+			return answer, nil
+		}
+	default:
+	}
+
+	conn, err := u.client.Dial(u.address)
+	if err != nil {
+		return nil, err
+	}
+	return u.exchangeWithConn(msg, conn)
+}
+
+// exchangeWithConn sends the query over the connection and keeps it for later queries if it succeeds
+func (u *tlsUpstream) exchangeWithConn(msg *dns.Msg, conn *dns.Conn) (*dns.Msg, error) {
+	answer, _, err := u.client.ExchangeWithConn(msg, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	select {
+	case u.idle <- conn:
+	default:
+		_ = conn.Close()
+	}
+	return answer, nil
+}
+
+// close closes the idle connections
+func (u *tlsUpstream) close() {
+	for {
+		select {
+		case conn := <-u.idle:
+			_ = conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// httpsUpstream sends the queries over HTTPS, reusing the connections between queries
+type httpsUpstream struct {
+	url    string
+	client *http.Client
+}
+
+func (u *httpsUpstream) exchange(msg *dns.Msg) (*dns.Msg, error) {
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.url, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", dohContentType)
+	request.Header.Set("Accept", dohContentType)
+
+	response, err := u.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+
+	answer := new(dns.Msg)
+	if err := answer.Unpack(body); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// close closes the idle connections
+func (u *httpsUpstream) close() {
+	u.client.CloseIdleConnections()
+}
+
+// forwarder serves plain DNS on a local address and forwards the queries to an
+// encrypted resolver. The DNS client opens a new connection for every DoT query
+// and keeps few DoH connections open, so the encrypted resolvers are given to it
+// through a forwarder which reuses its connections.
+type forwarder struct {
+	upstream upstream
+	servers  []*dns.Server
+	address  string
+}
+
+// startForwarder starts a forwarder listening on UDP and TCP on the same local port,
+// the latter being used by the DNS client to retry the queries with truncated answers
+func startForwarder(upstream upstream) (*forwarder, error) {
+	f := &forwarder{upstream: upstream}
+
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		var packetConn net.PacketConn
+		packetConn, err = net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		var listener net.Listener
+		listener, err = net.Listen("tcp", packetConn.LocalAddr().String())
+		if err != nil {
+			_ = packetConn.Close()
+			continue
+		}
+
+		f.address = packetConn.LocalAddr().String()
+		started := &sync.WaitGroup{}
+		f.servers = []*dns.Server{
+			{PacketConn: packetConn, Handler: f, NotifyStartedFunc: started.Done},
+			{Listener: listener, Handler: f, NotifyStartedFunc: started.Done},
+		}
+		started.Add(len(f.servers))
+		for _, server := range f.servers {
+			go func(server *dns.Server) { _ = server.ActivateAndServe() }(server)
+		}
+		started.Wait()
+		return f, nil
+	}
+	return nil, fmt.Errorf("could not start forwarder: %s", err)
+}
+
+// ServeDNS forwards the query to the upstream and answers with its response
+func (f *forwarder) ServeDNS(w dns.ResponseWriter, request *dns.Msg) {
+	answer, err := f.upstream.exchange(request)
+	if err != nil {
+		answer = new(dns.Msg).SetRcode(request, dns.RcodeServerFailure)
+	}
+	answer.Id = request.Id
+
+	if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+		size := dns.MinMsgSize
+		if opt := request.IsEdns0(); opt != nil {
+			size = int(opt.UDPSize())
+		}
+		answer.Truncate(size)
+	}
+	_ = w.WriteMsg(answer)
+}
+
+// close stops the forwarder and closes the connections to the upstream
+func (f *forwarder) close() error {
+	var errs []error
+	for _, server := range f.servers {
+		errs = append(errs, server.Shutdown())
+	}
+	closeUpstream(f.upstream)
+	return errors.Join(errs...)
+}
+
+// closeUpstream closes the idle connections of the upstream, if it keeps any
+func closeUpstream(upstream upstream) {
+	if closer, ok := upstream.(interface{ close() }); ok {
+		closer.close()
+	}
+}
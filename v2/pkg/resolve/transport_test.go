@@ -0,0 +1,124 @@
+package resolve
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answerA answers the query with an A record
+func answerA(req *dns.Msg, ip string) *dns.Msg {
+	msg := new(dns.Msg).SetReply(req)
+	msg.Answer = append(msg.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   net.ParseIP(ip),
+	})
+	return msg
+}
+
+func TestHTTPSUpstream(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := new(dns.Msg)
+		if r.Header.Get("Content-Type") != dohContentType || req.Unpack(body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		packed, _ := answerA(req, "192.0.2.1").Pack()
+		w.Header().Set("Content-Type", dohContentType)
+		_, _ = w.Write(packed)
+	}))
+	defer server.Close()
+
+	upstream := newUpstream(server.URL+"/dns-query", time.Second, 1, nil).(*httpsUpstream)
+	upstream.client = server.Client()
+
+	answer, err := upstream.exchange(new(dns.Msg).SetQuestion("www.example.com.", dns.TypeA))
+	require.Nil(t, err)
+	require.Len(t, answer.Answer, 1)
+	assert.Equal(t, "192.0.2.1", answer.Answer[0].(*dns.A).A.String())
+}
+
+func TestHTTPSUpstreamProxy(t *testing.T) {
+	var tunneled atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			tunneled.Store(r.Host)
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.Nil(t, err)
+
+	upstream := newUpstream("https://dns.example.com/dns-query", time.Second, 1, proxyURL)
+	_, err = upstream.exchange(new(dns.Msg).SetQuestion("www.example.com.", dns.TypeA))
+	require.NotNil(t, err)
+	assert.Equal(t, "dns.example.com:443", tunneled.Load())
+}
+
+func TestTLSUpstreamReusesConnections(t *testing.T) {
+	// The certificate of an httptest server is reused for the DoT server
+	certificateServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certificateServer.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", certificateServer.TLS)
+	require.Nil(t, err)
+	var connections atomic.Int32
+	server := &dns.Server{Listener: &countingListener{Listener: listener, count: &connections}, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		_ = w.WriteMsg(answerA(req, "192.0.2.2"))
+	})}
+	go func() { _ = server.ActivateAndServe() }()
+	defer func() { _ = server.Shutdown() }()
+
+	upstream := newUpstream("tls://"+listener.Addr().String(), time.Second, 1, nil).(*tlsUpstream)
+	upstream.client.TLSConfig = certificateServer.Client().Transport.(*http.Transport).TLSClientConfig
+	defer upstream.close()
+
+	for i := 0; i < 3; i++ {
+		answer, err := upstream.exchange(new(dns.Msg).SetQuestion("www.example.com.", dns.TypeA))
+		require.Nil(t, err)
+		require.Len(t, answer.Answer, 1)
+	}
+	assert.Equal(t, int32(1), connections.Load())
+}
+
+func TestForwarder(t *testing.T) {
+	plain := startDNSServer(t, func(string) []string {
+		return []string{"192.0.2.3"}
+	})
+	forwarder, err := startForwarder(newUpstream(plain, time.Second, 1, nil))
+	require.Nil(t, err)
+	defer func() { _ = forwarder.close() }()
+
+	dnsClient, err := dnsx.New(dnsx.Options{BaseResolvers: []string{forwarder.address}, MaxRetries: 1, QuestionTypes: DefaultRecordTypes})
+	require.Nil(t, err)
+	data, err := dnsClient.QueryMultiple("www.example.com")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"192.0.2.3"}, data.A)
+}
+
+// countingListener counts the accepted connections
+type countingListener struct {
+	net.Listener
+	count *atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.count.Add(1)
+	}
+	return conn, err
+}
@@ -0,0 +1,108 @@
+package resolve
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/rs/xid"
+)
+
+// wildcardZone contains the answers of a zone to random labels.
+// A zone without answers is not a wildcard zone.
+type wildcardZone struct {
+	// ready is closed once the zone has been probed
+	ready  chan struct{}
+	ips    map[string]struct{}
+	cnames map[string]struct{}
+	err    error
+}
+
+// WildcardCache probes every zone once for wildcard answers and caches the
+// result, so that wildcards are detected at any level under the input domain
+type WildcardCache struct {
+	resolver *Resolver
+	mutex    sync.Mutex
+	zones    map[string]*wildcardZone
+}
+
+// NewWildcardCache creates a cache of wildcard zones probed with the resolver,
+// which the resolution pools of the subdomains of a domain can share
+func (r *Resolver) NewWildcardCache() *WildcardCache {
+	return &WildcardCache{resolver: r, zones: make(map[string]*wildcardZone)}
+}
+
+// zone returns the wildcard answers of the zone, probing it on first use.
+// Concurrent callers wait for the probe started by the first one.
+func (c *WildcardCache) zone(name string) *wildcardZone {
+	c.mutex.Lock()
+	zone, ok := c.zones[name]
+	if ok {
+		c.mutex.Unlock()
+		<-zone.ready
+		return zone
+	}
+	zone = &wildcardZone{
+		ready:  make(chan struct{}),
+		ips:    make(map[string]struct{}),
+		cnames: make(map[string]struct{}),
+	}
+	c.zones[name] = zone
+	c.mutex.Unlock()
+
+	zone.err = zone.probe(c.resolver.client(), name)
+	close(zone.ready)
+	return zone
+}
+
+// probe resolves random labels under the zone and records their answers
+func (z *wildcardZone) probe(dnsClient *dnsx.DNSX, name string) error {
+	for i := 0; i < maxWildcardChecks; i++ {
+		data, err := dnsClient.QueryMultiple(xid.New().String() + "." + name)
+		if err != nil {
+			return fmt.Errorf("could not probe %s for wildcards: %s", name, err)
+		}
+		if len(data.A) == 0 && len(data.AAAA) == 0 && len(data.CNAME) == 0 {
+			return nil
+		}
+
+		for _, ip := range append(data.A, data.AAAA...) {
+			z.ips[ip] = struct{}{}
+		}
+		for _, cname := range data.CNAME {
+			z.cnames[cname] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// match returns why the records match the wildcard answers of the zone, if they do
+func (z *wildcardZone) match(records Records) (string, bool) {
+	for _, cname := range records.CNAME {
+		if _, ok := z.cnames[cname]; ok {
+			return fmt.Sprintf("cname %s", cname), true
+		}
+	}
+	for _, ip := range records.IPs() {
+		if _, ok := z.ips[ip]; ok {
+			return fmt.Sprintf("ip %s", ip), true
+		}
+	}
+	return "", false
+}
+
+// parentZones returns the zones from the input domain down to the parent of the host
+func parentZones(domain, host string) []string {
+	zones := []string{domain}
+	subdomain, ok := strings.CutSuffix(host, "."+domain)
+	if !ok {
+		return zones
+	}
+
+	labels := strings.Split(subdomain, ".")
+	for i := len(labels) - 1; i > 0; i-- {
+		zones = append(zones, strings.Join(labels[i:], ".")+"."+domain)
+	}
+	return zones
+}
@@ -0,0 +1,57 @@
+package resolve
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParentZones(t *testing.T) {
+	assert.Equal(t, []string{"example.com"}, parentZones("example.com", "www.example.com"))
+	assert.Equal(t, []string{"example.com", "dev.example.com", "api.dev.example.com"}, parentZones("example.com", "v1.api.dev.example.com"))
+	assert.Equal(t, []string{"example.com"}, parentZones("example.com", "www.example.org"))
+}
+
+func TestWildcardZoneMatch(t *testing.T) {
+	zone := &wildcardZone{
+		ips:    map[string]struct{}{"192.0.2.1": {}},
+		cnames: map[string]struct{}{"lb.example.net": {}},
+	}
+
+	reason, ok := zone.match(Records{A: []string{"192.0.2.2"}, AAAA: []string{"2001:db8::1"}})
+	assert.False(t, ok, reason)
+
+	reason, ok = zone.match(Records{A: []string{"192.0.2.2", "192.0.2.1"}})
+	assert.True(t, ok)
+	assert.Equal(t, "ip 192.0.2.1", reason)
+
+	reason, ok = zone.match(Records{A: []string{"198.51.100.1"}, CNAME: []string{"edge.example.net", "lb.example.net"}})
+	assert.True(t, ok)
+	assert.Equal(t, "cname lb.example.net", reason)
+}
+
+func TestWildcardCacheShared(t *testing.T) {
+	var queries atomic.Int32
+	server := startDNSServer(t, func(string) []string {
+		queries.Add(1)
+		return []string{"192.0.2.1"}
+	})
+	resolver := New()
+	require.Nil(t, resolver.SetResolvers([]string{server}, dnsx.Options{MaxRetries: 1, QuestionTypes: DefaultRecordTypes}))
+
+	first := resolver.NewResolutionPool(1, true)
+	require.Nil(t, first.InitWildcards("example.com"))
+	close(first.Tasks)
+	probes := queries.Load()
+	require.NotZero(t, probes)
+
+	// A pool sharing the cache does not probe the domain again
+	second := resolver.NewResolutionPool(1, true)
+	second.Wildcards = first.Wildcards
+	require.Nil(t, second.InitWildcards("example.com"))
+	close(second.Tasks)
+	assert.Equal(t, probes, queries.Load())
+}
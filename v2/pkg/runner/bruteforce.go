@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// bruteforceSource is the source of the subdomains found by resolving the words of the wordlist
+const bruteforceSource = "bruteforce"
+
+// bruteforce resolves a candidate for every word of the wordlist under the domain
+// and returns the candidates which resolve to non wildcard answers as results
+func (r *Runner) bruteforce(ctx context.Context, domain string, wildcards *resolve.WildcardCache, resolved *resolvedRecords, statistics *subscraping.StatisticsCollector) <-chan subscraping.Result {
+	candidates := make([]string, 0, len(r.wordlist))
+	for _, word := range r.wordlist {
+		candidates = append(candidates, word+"."+domain)
+	}
+	return r.resolveCandidates(ctx, domain, bruteforceSource, candidates, wildcards, resolved, statistics)
+}
+
+// resolvedRecords holds the records of the candidates found under a domain, so
+// that the resolution of the results of the domain does not query them again
+type resolvedRecords struct {
+	mutex   sync.Mutex
+	records map[string]resolve.Records
+}
+
+func newResolvedRecords() *resolvedRecords {
+	return &resolvedRecords{records: make(map[string]resolve.Records)}
+}
+
+func (r *resolvedRecords) add(host string, records resolve.Records) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.records[host] = records
+}
+
+// get returns the records of the host, nil if it was not resolved
+func (r *resolvedRecords) get(host string) *resolve.Records {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	records, ok := r.records[host]
+	if !ok {
+		return nil
+	}
+	return &records
+}
+
+// resolveCandidates resolves the candidate subdomains of the domain and returns the
+// candidates which resolve to non wildcard answers as results of the given source
+func (r *Runner) resolveCandidates(ctx context.Context, domain, source string, candidates []string, wildcards *resolve.WildcardCache, resolved *resolvedRecords, statistics *subscraping.StatisticsCollector) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	if len(candidates) == 0 || ctx.Err() != nil {
+		close(results)
+		return results
+	}
+
+	startTime := time.Now()
+	statistics.AddSource(source)
+	resolutionPool := r.resolverClient.NewResolutionPool(r.options.Threads, true)
+	resolutionPool.Wildcards = wildcards
+	if err := resolutionPool.InitWildcards(domain); err != nil {
+		gologger.Warning().Msgf("Could not get wildcards for domain %s: %s\n", domain, err)
+	}
+
+	go func() {
+		defer close(resolutionPool.Tasks)
+		for _, candidate := range candidates {
+			// The select picks the send at random when the context is also done
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case resolutionPool.Tasks <- resolve.HostEntry{Domain: domain, Host: candidate, Source: source}:
+			}
+		}
+	}()
+
+	go func() {
+		defer close(results)
+		for result := range resolutionPool.Results {
+			switch result.Type {
+			case resolve.Error:
+				statistics.AddError(source)
+			case resolve.Subdomain:
+				statistics.AddResult(source)
+				resolved.add(result.Host, result.Records)
+				results <- subscraping.Result{Type: subscraping.Subdomain, Source: source, Value: result.Host}
+			}
+		}
+		statistics.AddTimeTaken(source, time.Since(startTime))
+	}()
+	return results
+}
+
+// mergeResults merges the results of several channels into a single channel
+func mergeResults(channels ...<-chan subscraping.Result) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+
+	wg := &sync.WaitGroup{}
+	for _, channel := range channels {
+		wg.Add(1)
+		go func(channel <-chan subscraping.Result) {
+			defer wg.Done()
+			for result := range channel {
+				results <- result
+			}
+		}(channel)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// newStubResolver returns a resolver querying a local DNS server which knows
+// the addresses of the given hosts and answers NXDOMAIN for any other name
+func newStubResolver(t *testing.T, hosts map[string]string) *resolve.Resolver {
+	resolver := resolve.New()
+	require.Nil(t, resolver.SetResolvers([]string{startStubServer(t, hosts)}, dnsx.Options{MaxRetries: 1, QuestionTypes: resolve.DefaultRecordTypes}))
+	return resolver
+}
+
+func TestBruteforce(t *testing.T) {
+	runner := &Runner{
+		options:        &Options{Threads: 2},
+		wordlist:       []string{"www", "missing", "api"},
+		resolverClient: newStubResolver(t, map[string]string{"www.example.com.": "192.0.2.1", "api.example.com.": "192.0.2.2"}),
+	}
+
+	statistics := subscraping.NewStatisticsCollector()
+	resolved := newResolvedRecords()
+	var hosts []string
+	for result := range runner.bruteforce(context.Background(), "example.com", runner.resolverClient.NewWildcardCache(), resolved, statistics) {
+		assert.Equal(t, bruteforceSource, result.Source)
+		hosts = append(hosts, result.Value)
+	}
+	assert.ElementsMatch(t, []string{"www.example.com", "api.example.com"}, hosts)
+	assert.Equal(t, 2, statistics.Statistics()[bruteforceSource].Results)
+
+	// The records of the hits are kept for the resolution of the results
+	require.NotNil(t, resolved.get("www.example.com"))
+	assert.Equal(t, []string{"192.0.2.1"}, resolved.get("www.example.com").A)
+	assert.Nil(t, resolved.get("missing.example.com"))
+
+	// Nothing is resolved once the enumeration is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for result := range runner.bruteforce(ctx, "example.com", runner.resolverClient.NewWildcardCache(), newResolvedRecords(), statistics) {
+		t.Fatalf("result %s found with a cancelled context", result.Value)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/passive"
-	"github.com/projectdiscovery/gologger"
 	fileutil "github.com/projectdiscovery/utils/file"
 )
 
@@ -20,7 +19,7 @@ func createProviderConfigYAML(configFilePath string) error {
 	defer configFile.Close()
 
 	sourcesRequiringApiKeysMap := make(map[string][]string)
-	for _, source := range passive.AllSources {
+	for _, source := range passive.NewSources() {
 		if source.NeedsKey() {
 			sourceName := strings.ToLower(source.Name())
 			sourcesRequiringApiKeysMap[sourceName] = []string{}
@@ -30,22 +29,14 @@ func createProviderConfigYAML(configFilePath string) error {
 	return yaml.NewEncoder(configFile).Encode(sourcesRequiringApiKeysMap)
 }
 
-// UnmarshalFrom writes the marshaled yaml config to disk
-func UnmarshalFrom(file string) error {
+// UnmarshalFrom reads the API keys of the sources from the provider config file
+func UnmarshalFrom(file string) (map[string][]string, error) {
 	reader, err := fileutil.SubstituteConfigFromEnvVars(file)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	sourceApiKeysMap := map[string][]string{}
 	err = yaml.NewDecoder(reader).Decode(sourceApiKeysMap)
-	for _, source := range passive.AllSources {
-		sourceName := strings.ToLower(source.Name())
-		apiKeys := sourceApiKeysMap[sourceName]
-		if source.NeedsKey() && apiKeys != nil && len(apiKeys) > 0 {
-			gologger.Debug().Msgf("API key(s) found for %s.", sourceName)
-			source.AddApiKeys(apiKeys)
-		}
-	}
-	return err
+	return sourceApiKeysMap, err
 }
@@ -34,23 +34,60 @@ func (r *Runner) EnumerateSingleDomain(domain string, writers []io.Writer) (map[
 
 // EnumerateSingleDomainWithCtx performs subdomain enumeration against a single domain
 func (r *Runner) EnumerateSingleDomainWithCtx(ctx context.Context, domain string, writers []io.Writer) (map[string]map[string]struct{}, error) {
+	return r.enumerateSingleDomain(ctx, domain, writers)
+}
+
+func (r *Runner) enumerateSingleDomain(ctx context.Context, domain string, writers []io.Writer, options ...passive.EnumerateOption) (map[string]map[string]struct{}, error) {
 	gologger.Info().Msgf("Enumerating subdomains for %s\n", domain)
 
+	// The wildcards of the domain are probed once for every resolution pool of the domain,
+	// and the candidates found by resolving them are not resolved again
+	wildcards := r.resolverClient.NewWildcardCache()
+	resolved := newResolvedRecords()
+
 	// Check if the user has asked to remove wildcards explicitly.
 	// If yes, create the resolution pool and get the wildcards for the current domain
 	var resolutionPool *resolve.ResolutionPool
 	if r.options.RemoveWildcard {
-		resolutionPool = r.resolverClient.NewResolutionPool(r.options.Threads, r.options.RemoveWildcard)
-		err := resolutionPool.InitWildcards(domain)
-		if err != nil {
-			// Log the error but don't quit.
-			gologger.Warning().Msgf("Could not get wildcards for domain %s: %s\n", domain, err)
-		}
+		resolutionPool = r.newResolutionPool(domain, wildcards)
+	}
+
+	// Restore the progress of an interrupted run, skipping the sources which already completed
+	completedSources, restoredHosts, err := r.resume.start(domain)
+	if err != nil {
+		gologger.Warning().Msgf("Could not save checkpoint for %s: %s\n", domain, err)
+	}
+	if len(completedSources) > 0 {
+		gologger.Info().Msgf("Resuming %s, skipping completed sources: %s\n", domain, strings.Join(completedSources, ", "))
 	}
 
 	// Run the passive subdomain enumeration
 	now := time.Now()
-	passiveResults := r.passiveAgent.EnumerateSubdomainsWithCtx(ctx, domain, r.options.Proxy, r.options.RateLimit, r.options.Timeout, time.Duration(r.options.MaxEnumerationTime)*time.Minute, r.options.RespFileDirectory, passive.WithCustomRateLimit(r.rateLimit))
+	// The active stages enumerating alongside the sources stop with them after the maximum enumeration time
+	maxEnumerationTime := time.Duration(r.options.MaxEnumerationTime) * time.Minute
+	enumerationCtx, cancelEnumeration := context.WithTimeout(ctx, maxEnumerationTime)
+	defer cancelEnumeration()
+	statistics := subscraping.NewStatisticsCollector()
+	recursiveOptions := append([]passive.EnumerateOption{
+		passive.WithCustomRateLimit(r.rateLimit),
+		passive.WithStatistics(statistics),
+		passive.WithPageLimits(r.options.pageLimits),
+		passive.WithRetryPolicy(r.options.retryPolicy(), r.options.retryPolicies),
+		passive.WithRecursiveSourcesOnly(),
+	}, options...)
+	options = append([]passive.EnumerateOption{
+		passive.WithCustomRateLimit(r.rateLimit),
+		passive.WithStatistics(statistics),
+		passive.WithPageLimits(r.options.pageLimits),
+		passive.WithRetryPolicy(r.options.retryPolicy(), r.options.retryPolicies),
+		passive.WithExcludedSources(completedSources...),
+		passive.WithSourceCompleted(func(source string) { r.resume.sourceCompleted(domain, source) }),
+	}, options...)
+	passiveResults := mergeResults(
+		r.passiveAgent.EnumerateSubdomainsWithCtx(ctx, domain, r.options.Proxy, r.options.RateLimit, r.options.Timeout, maxEnumerationTime, r.options.RespFileDirectory, options...),
+		r.bruteforce(enumerationCtx, domain, wildcards, resolved, statistics),
+		r.enumerateZone(enumerationCtx, domain, statistics),
+	)
 
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
@@ -58,50 +95,121 @@ func (r *Runner) EnumerateSingleDomainWithCtx(ctx context.Context, domain string
 	uniqueMap := make(map[string]resolve.HostEntry)
 	// Create a map to track sources for each host
 	sourceMap := make(map[string]map[string]struct{})
-	skippedCounts := make(map[string]int)
+	// Create a map to merge the metadata of each source for each host
+	metadataMap := make(map[string]map[string]*subscraping.Metadata)
+	outputWriter := NewOutputWriter(r.options.JSON)
+	// streamErr holds the first error encountered while streaming results
+	var streamErr error
+	// knownHosts holds the hosts found by previous runs, left out of the output in new-only mode
+	knownHosts := make(map[string]struct{})
+	// newHosts holds the hosts found at the current depth, enumerated recursively at the next depth
+	var newHosts []string
+
+	// processResult deduplicates a subdomain found by a source and sends it
+	// for resolution or output. The parent is the subdomain enumerated
+	// recursively which led to the result. Statistics are not recorded for
+	// subdomains restored from the checkpoint of an interrupted run.
+	processResult := func(result subscraping.Result, parent string, restored bool) {
+		subdomain := replacer.Replace(result.Value)
+
+		// Validate the subdomain found and remove wildcards from
+		if !strings.HasSuffix(subdomain, "."+domain) {
+			if !restored {
+				statistics.AddOutOfScope(result.Source)
+			}
+			return
+		}
+		if matchSubdomain := r.filterAndMatchSubdomain(subdomain); !matchSubdomain {
+			if !restored {
+				statistics.AddFiltered(result.Source)
+			}
+			return
+		}
+		if _, ok := uniqueMap[subdomain]; !ok {
+			sourceMap[subdomain] = make(map[string]struct{})
+		}
+
+		// Log the verbose message about the found subdomain per source
+		if _, ok := sourceMap[subdomain][result.Source]; !ok {
+			gologger.Verbose().Label(result.Source).Msg(subdomain)
+		}
+
+		sourceMap[subdomain][result.Source] = struct{}{}
+		if r.options.Metadata {
+			r.mergeMetadata(metadataMap, subdomain, result)
+		}
+
+		// Check if the subdomain is a duplicate. If not,
+		// send the subdomain for resolution.
+		if _, ok := uniqueMap[subdomain]; ok {
+			if !restored {
+				statistics.AddDuplicate(result.Source)
+			}
+			return
+		}
+
+		hostEntry := resolve.HostEntry{Domain: domain, Host: subdomain, Source: result.Source, Parent: parent}
+		hostEntry.Records = resolved.get(subdomain)
+
+		uniqueMap[subdomain] = hostEntry
+		newHosts = append(newHosts, subdomain)
+		if r.options.NewOnly && r.isKnownHost(domain, subdomain) {
+			knownHosts[subdomain] = struct{}{}
+			return
+		}
+		// If the user asked to remove wildcard then send on the resolve
+		// queue. Otherwise, in stream mode write the result to the
+		// writers as soon as it is discovered.
+		if r.options.RemoveWildcard {
+			resolutionPool.Tasks <- hostEntry
+		} else if r.options.Stream && streamErr == nil {
+			streamErr = r.streamHost(outputWriter, domain, hostEntry, writers)
+		}
+	}
 
 	// Process the results in a separate goroutine
 	go func() {
+		for host, sources := range restoredHosts {
+			for _, source := range sources {
+				processResult(subscraping.Result{Type: subscraping.Subdomain, Source: source, Value: host}, "", true)
+			}
+		}
 		for result := range passiveResults {
 			switch result.Type {
 			case subscraping.Error:
 				gologger.Warning().Msgf("Encountered an error with source %s: %s\n", result.Source, result.Error)
 			case subscraping.Subdomain:
-				subdomain := replacer.Replace(result.Value)
-
-				// Validate the subdomain found and remove wildcards from
-				if !strings.HasSuffix(subdomain, "."+domain) {
-					skippedCounts[result.Source]++
-					continue
-				}
-				if matchSubdomain := r.filterAndMatchSubdomain(subdomain); matchSubdomain {
-					if _, ok := uniqueMap[subdomain]; !ok {
-						sourceMap[subdomain] = make(map[string]struct{})
-					}
-
-					// Log the verbose message about the found subdomain per source
-					if _, ok := sourceMap[subdomain][result.Source]; !ok {
-						gologger.Verbose().Label(result.Source).Msg(subdomain)
-					}
-
-					sourceMap[subdomain][result.Source] = struct{}{}
+				processResult(result, "", false)
+			}
+			if err := r.resume.flush(domain, sourceMap); err != nil {
+				gologger.Warning().Msgf("Could not save checkpoint for %s: %s\n", domain, err)
+			}
+		}
 
-					// Check if the subdomain is a duplicate. If not,
-					// send the subdomain for resolution.
-					if _, ok := uniqueMap[subdomain]; ok {
-						skippedCounts[result.Source]++
-						continue
-					}
+		// Resolve the permutations of the subdomains found so far
+		if r.options.Permute && enumerationCtx.Err() == nil {
+			for result := range r.permute(enumerationCtx, domain, newHosts, wildcards, resolved, statistics) {
+				processResult(result, "", false)
+			}
+		}
 
-					hostEntry := resolve.HostEntry{Domain: domain, Host: subdomain, Source: result.Source}
+		// Feed the subdomains found back into the recursive sources, one depth at a time,
+		// within the time left of the maximum enumeration time of the domain
+		enumerated := map[string]struct{}{domain: {}}
+		for depth := 1; depth <= r.options.RecursiveDepth && enumerationCtx.Err() == nil; depth++ {
+			targets := recursiveTargets(domain, newHosts, enumerated, r.options.RecursiveBreadth)
+			if len(targets) == 0 {
+				break
+			}
+			gologger.Info().Msgf("Enumerating %d subdomains of %s recursively at depth %d\n", len(targets), domain, depth)
 
-					uniqueMap[subdomain] = hostEntry
-					// If the user asked to remove wildcard then send on the resolve
-					// queue. Otherwise, if mode is not verbose print the results on
-					// the screen as they are discovered.
-					if r.options.RemoveWildcard {
-						resolutionPool.Tasks <- hostEntry
-					}
+			newHosts = nil
+			for result := range r.enumerateTargets(enumerationCtx, targets, recursiveOptions) {
+				switch result.Type {
+				case subscraping.Error:
+					gologger.Warning().Msgf("Encountered an error with source %s for %s: %s\n", result.Source, result.target, result.Error)
+				case subscraping.Subdomain:
+					processResult(result.Result, result.target, false)
 				}
 			}
 		}
@@ -115,26 +223,110 @@ func (r *Runner) EnumerateSingleDomainWithCtx(ctx context.Context, domain string
 	// If the user asked to remove wildcards, listen from the results
 	// queue and write to the map. At the end, print the found results to the screen
 	foundResults := make(map[string]resolve.Result)
-	if r.options.RemoveWildcard {
+	// The hosts whose CNAME chain does not lead to an address are only kept
+	// apart for the takeover check, which adds the candidates to the results
+	danglingResults := make(map[string]resolve.Result)
+	collectResults := func(resolutionPool *resolve.ResolutionPool) {
 		// Process the results coming from the resolutions pool
 		for result := range resolutionPool.Results {
 			switch result.Type {
 			case resolve.Error:
 				gologger.Warning().Msgf("Could not resolve host: %s\n", result.Error)
 			case resolve.Subdomain:
+				if result.IP == "" {
+					danglingResults[result.Host] = result
+					continue
+				}
 				// Add the found subdomain to a map.
 				if _, ok := foundResults[result.Host]; !ok {
+					result.ASN = r.asnDB.Lookup(result.Records.IPs())
+					r.asnSummary.Add(result.Host, result.ASN)
 					foundResults[result.Host] = result
+					if r.options.Stream && streamErr == nil {
+						streamErr = r.streamResolvedHost(outputWriter, domain, result, writers)
+					}
 				}
 			}
 		}
 	}
+	if r.options.RemoveWildcard {
+		collectResults(resolutionPool)
+	}
 	wg.Wait()
-	outputWriter := NewOutputWriter(r.options.JSON)
-	// Now output all results in output writers
-	var err error
+
+	// resolveResults resolves the subdomains found by an active stage run
+	// once the resolution of the subdomains found so far is complete
+	resolveResults := func(results <-chan subscraping.Result) {
+		resolutionPool = r.newResolutionPool(domain, wildcards)
+		go func() {
+			for result := range results {
+				processResult(result, "", false)
+			}
+			close(resolutionPool.Tasks)
+		}()
+		collectResults(resolutionPool)
+	}
+
+	// Sweep the netblocks of the resolved IPs for PTR records pointing back into the domain
+	if r.options.PTRSweep && ctx.Err() == nil {
+		netblocks := ptrNetblocks(foundResults, r.options.PTRNetblock)
+		resolveResults(r.sweepPTR(ctx, domain, netblocks, statistics))
+	}
+	// Read the certificates served by the resolved hosts for names in scope of the domain
+	if r.options.TLSProbe && ctx.Err() == nil {
+		targets := tlsProbeTargets(foundResults, r.options.TLSPorts)
+		resolveResults(r.probeTLS(ctx, domain, targets, statistics))
+	}
+	// Request the resolved hosts over HTTP, recording their responses in the output
+	if r.options.Probe && ctx.Err() == nil {
+		r.probeResolved(ctx, domain, foundResults, resolveResults, statistics)
+	}
+	// Flag the resolved hosts whose CNAME targets could be claimed on a third-party service
+	if r.options.Takeover && ctx.Err() == nil {
+		r.checkTakeovers(ctx, domain, foundResults, danglingResults)
+	}
+	if streamErr != nil {
+		gologger.Error().Msgf("Could not write results for %s: %s\n", domain, streamErr)
+		return nil, streamErr
+	}
+
+	if r.resultDB != nil {
+		// Only the hosts output are known to the next runs, so that the hosts which did not
+		// resolve or were removed as wildcards are still new once they resolve
+		savedHosts := sourceMap
+		if r.options.RemoveWildcard {
+			savedHosts = make(map[string]map[string]struct{}, len(foundResults))
+			for host := range foundResults {
+				savedHosts[host] = sourceMap[host]
+			}
+		}
+		if err := r.resultDB.Save(domain, savedHosts, now); err != nil {
+			gologger.Warning().Msgf("Could not save results for %s to the database: %s\n", domain, err)
+		}
+	}
+	// Leave the hosts found by previous runs out of the output
+	for host := range knownHosts {
+		delete(uniqueMap, host)
+		delete(sourceMap, host)
+		delete(metadataMap, host)
+	}
+
+	// Now output all results in output writers. In stream mode everything
+	// has already been written, except the sources of each host which are
+	// only complete once every source has finished.
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
 	for _, writer := range writers {
-		if r.options.HostIP {
+		if r.options.Stream && !r.writesOutputAtEnd() {
+			break
+		}
+		if r.options.Metadata {
+			var resolved map[string]resolve.Result
+			if r.options.RemoveWildcard {
+				resolved = foundResults
+			}
+			err = outputWriter.WriteHostMetadata(domain, metadataMap, resolved, writer)
+		} else if r.options.HostIP {
 			err = outputWriter.WriteHostIP(domain, foundResults, writer)
 		} else {
 			if r.options.RemoveWildcard {
@@ -162,10 +354,10 @@ func (r *Runner) EnumerateSingleDomainWithCtx(ctx context.Context, domain string
 		numberOfSubDomains = len(uniqueMap)
 	}
 
-	if r.options.ResultCallback != nil {
+	if r.options.ResultCallback != nil && !r.options.Stream {
 		if r.options.RemoveWildcard {
 			for host, result := range foundResults {
-				r.options.ResultCallback(&resolve.HostEntry{Domain: host, Host: result.Host, Source: result.Source})
+				r.options.ResultCallback(&resolve.HostEntry{Domain: host, Host: result.Host, Source: result.Source, Parent: result.Parent})
 			}
 		} else {
 			for _, v := range uniqueMap {
@@ -175,23 +367,100 @@ func (r *Runner) EnumerateSingleDomainWithCtx(ctx context.Context, domain string
 	}
 	gologger.Info().Msgf("Found %d subdomains for %s in %s\n", numberOfSubDomains, domain, duration)
 
+	r.statisticsMutex.Lock()
+	r.statistics[domain] = statistics.Statistics()
+	r.statisticsMutex.Unlock()
 	if r.options.Statistics {
 		gologger.Info().Msgf("Printing source statistics for %s", domain)
-		statistics := r.passiveAgent.GetStatistics()
-		// This is a hack to remove the skipped count from the statistics
-		// as we don't want to show it in the statistics.
-		// TODO: Design a better way to do this.
-		for source, count := range skippedCounts {
-			if stat, ok := statistics[source]; ok {
-				stat.Results -= count
-				statistics[source] = stat
-			}
+		printStatistics(statistics.Statistics())
+	}
+
+	// A domain interrupted by the cancellation of the run is enumerated again on resume
+	if ctx.Err() == nil {
+		if err := r.resume.complete(domain); err != nil {
+			gologger.Warning().Msgf("Could not save checkpoint for %s: %s\n", domain, err)
 		}
-		printStatistics(statistics)
 	}
 	return sourceMap, nil
 }
 
+// isKnownHost returns true if the host was found for the domain by a previous run
+func (r *Runner) isKnownHost(domain, host string) bool {
+	if r.resultDB == nil {
+		return false
+	}
+	known, err := r.resultDB.IsKnown(domain, host)
+	if err != nil {
+		gologger.Warning().Msgf("Could not look up %s in the database: %s\n", host, err)
+	}
+	return known
+}
+
+// writesOutputAtEnd returns true if the output contains all the sources
+// of a host, its probe responses or its takeover check, which forces the
+// output to be written once enumeration ends.
+func (r *Runner) writesOutputAtEnd() bool {
+	return r.options.Metadata || (r.options.CaptureSources && !r.options.RemoveWildcard) || r.options.Probe || r.options.Takeover
+}
+
+// mergeMetadata merges the metadata of the result with the
+// metadata previously returned by the same source for the host
+func (r *Runner) mergeMetadata(metadataMap map[string]map[string]*subscraping.Metadata, host string, result subscraping.Result) {
+	if _, ok := metadataMap[host]; !ok {
+		metadataMap[host] = make(map[string]*subscraping.Metadata)
+	}
+	metadata, ok := metadataMap[host][result.Source]
+	if !ok {
+		metadata = &subscraping.Metadata{}
+		metadataMap[host][result.Source] = metadata
+	}
+	metadata.Merge(result.Metadata)
+}
+
+// streamHost writes a freshly deduplicated host to all the writers
+// and notifies the result callback
+func (r *Runner) streamHost(outputWriter *OutputWriter, domain string, hostEntry resolve.HostEntry, writers []io.Writer) error {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+	if !r.writesOutputAtEnd() {
+		for _, writer := range writers {
+			if err := outputWriter.WriteHost(domain, map[string]resolve.HostEntry{hostEntry.Host: hostEntry}, writer); err != nil {
+				return err
+			}
+		}
+	}
+	if r.options.ResultCallback != nil {
+		r.options.ResultCallback(&hostEntry)
+	}
+	return nil
+}
+
+// streamResolvedHost writes a freshly resolved host to all the writers
+// and notifies the result callback
+func (r *Runner) streamResolvedHost(outputWriter *OutputWriter, domain string, result resolve.Result, writers []io.Writer) error {
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+	results := map[string]resolve.Result{result.Host: result}
+	for _, writer := range writers {
+		if r.writesOutputAtEnd() {
+			break
+		}
+		var err error
+		if r.options.HostIP {
+			err = outputWriter.WriteHostIP(domain, results, writer)
+		} else {
+			err = outputWriter.WriteHostNoWildcard(domain, results, writer)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if r.options.ResultCallback != nil {
+		r.options.ResultCallback(&resolve.HostEntry{Domain: domain, Host: result.Host, Source: result.Source, Parent: result.Parent})
+	}
+	return nil
+}
+
 func (r *Runner) filterAndMatchSubdomain(subdomain string) bool {
 	if r.options.filterRegexes != nil {
 		for _, filter := range r.options.filterRegexes {
@@ -210,3 +479,18 @@ func (r *Runner) filterAndMatchSubdomain(subdomain string) bool {
 	}
 	return true
 }
+
+// newResolutionPool creates the pool resolving the subdomains of the domain and probes
+// the domain for wildcards, unless the cache of wildcards already holds its answers
+func (r *Runner) newResolutionPool(domain string, wildcards *resolve.WildcardCache) *resolve.ResolutionPool {
+	resolutionPool := r.resolverClient.NewResolutionPool(r.options.Threads, r.options.RemoveWildcard)
+	resolutionPool.LabelWildcards = r.options.LabelWildcard
+	resolutionPool.KeepDangling = r.options.Takeover
+	resolutionPool.Wildcards = wildcards
+	err := resolutionPool.InitWildcards(domain)
+	if err != nil {
+		// Log the error but don't quit.
+		gologger.Warning().Msgf("Could not get wildcards for domain %s: %s\n", domain, err)
+	}
+	return resolutionPool
+}
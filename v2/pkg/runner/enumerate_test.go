@@ -1,12 +1,150 @@
 package runner
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	jsoniter "github.com/json-iterator/go"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/passive"
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
 
+// staticSource returns the subdomains given by a function for each domain
+type staticSource struct {
+	name       string
+	subdomains func(domain string) []string
+}
+
+func (s *staticSource) Run(_ context.Context, domain string, _ *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	go func() {
+		defer close(results)
+		for _, subdomain := range s.subdomains(domain) {
+			results <- subscraping.Result{Source: s.name, Type: subscraping.Subdomain, Value: subdomain}
+		}
+	}()
+	return results
+}
+
+func (s *staticSource) Name() string {
+	return s.name
+}
+
+func (s *staticSource) IsDefault() bool {
+	return false
+}
+
+func (s *staticSource) HasRecursiveSupport() bool {
+	return false
+}
+
+func (s *staticSource) NeedsKey() bool {
+	return false
+}
+
+func (s *staticSource) AddApiKeys(_ []string) {}
+
+// stepSource returns a first subdomain, then waits for it to be written before returning the others
+type stepSource struct {
+	writes chan struct{}
+	// streamed records whether the first subdomain was written before the others were returned
+	streamed bool
+}
+
+func (s *stepSource) Run(_ context.Context, domain string, _ *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	go func() {
+		defer close(results)
+		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: "a." + domain}
+		select {
+		case <-s.writes:
+			s.streamed = true
+		case <-time.After(500 * time.Millisecond):
+		}
+		for _, subdomain := range []string{"b." + domain, "a." + domain} {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
+		}
+	}()
+	return results
+}
+
+func (s *stepSource) Name() string {
+	return "stepsource"
+}
+
+func (s *stepSource) IsDefault() bool {
+	return false
+}
+
+func (s *stepSource) HasRecursiveSupport() bool {
+	return false
+}
+
+func (s *stepSource) NeedsKey() bool {
+	return false
+}
+
+func (s *stepSource) AddApiKeys(_ []string) {}
+
+// signalWriter signals every write made to it
+type signalWriter struct {
+	bytes.Buffer
+	writes chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	select {
+	case w.writes <- struct{}{}:
+	default:
+	}
+	return w.Buffer.Write(p)
+}
+
+// registerSource registers the source for the duration of the test
+func registerSource(t *testing.T, source subscraping.Source) {
+	require.Nil(t, passive.RegisterSource(func() subscraping.Source { return source }))
+	t.Cleanup(func() { passive.UnregisterSource(source.Name()) })
+}
+
+// startStubServer starts a local DNS server which knows the addresses of the
+// given hosts and answers NXDOMAIN for any other name, returning its address
+func startStubServer(t *testing.T, hosts map[string]string) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg).SetReply(req)
+		question := req.Question[0]
+		ip, ok := hosts[question.Name]
+		if !ok {
+			msg.Rcode = dns.RcodeNameError
+		} else if question.Qtype == dns.TypeA {
+			msg.Answer = append(msg.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP(ip),
+			})
+		}
+		_ = w.WriteMsg(msg)
+	})}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return conn.LocalAddr().String()
+}
+
 func TestFilterAndMatchSubdomain(t *testing.T) {
 	options := &Options{}
 	options.Domain = []string{"example.com"}
@@ -143,3 +281,151 @@ func TestFilterAndMatchSubdomain(t *testing.T) {
 		}
 	})
 }
+
+func TestNewOnlyResolvedHosts(t *testing.T) {
+	registerSource(t, &staticSource{name: "staticsource", subdomains: func(domain string) []string {
+		return []string{"www." + domain, "dev." + domain}
+	}})
+	database := filepath.Join(t.TempDir(), "results.db")
+
+	// run enumerates the domain with a resolver knowing the given hosts and returns the output
+	run := func(hosts map[string]string) []string {
+		output := &bytes.Buffer{}
+		options := &Options{
+			Domain:             []string{"example.com"},
+			Sources:            []string{"staticsource"},
+			Threads:            2,
+			Timeout:            10,
+			MaxEnumerationTime: 1,
+			RemoveWildcard:     true,
+			Resolvers:          []string{startStubServer(t, hosts)},
+			NoResolverCheck:    true,
+			ResultDatabase:     database,
+			NewOnly:            true,
+			Output:             output,
+			ProviderApiKeys:    map[string][]string{},
+		}
+		runner, err := NewRunner(options)
+		require.Nil(t, err)
+		defer runner.Close()
+		require.Nil(t, runner.RunEnumeration())
+		return strings.Fields(output.String())
+	}
+
+	assert.Equal(t, []string{"www.example.com"}, run(map[string]string{"www.example.com.": "192.0.2.1"}))
+	// The host which did not resolve is new once it resolves, unlike the host output before
+	assert.Equal(t, []string{"dev.example.com"}, run(map[string]string{"www.example.com.": "192.0.2.1", "dev.example.com.": "192.0.2.2"}))
+	assert.Empty(t, run(map[string]string{"www.example.com.": "192.0.2.1", "dev.example.com.": "192.0.2.2"}))
+}
+
+func TestStreamOutput(t *testing.T) {
+	source := &stepSource{}
+	registerSource(t, source)
+
+	// run enumerates the domain in stream mode and returns the
+	// hosts written along with the hosts passed to the callback
+	run := func(captureSources bool) ([]string, map[string]int) {
+		source.writes, source.streamed = make(chan struct{}, 1), false
+		output := &signalWriter{writes: source.writes}
+		callbacks := make(map[string]int)
+		options := &Options{
+			Domain:             []string{"example.com"},
+			Sources:            []string{"stepsource"},
+			Threads:            2,
+			Timeout:            10,
+			MaxEnumerationTime: 1,
+			JSON:               true,
+			Stream:             true,
+			CaptureSources:     captureSources,
+			Output:             output,
+			ProviderApiKeys:    map[string][]string{},
+			ResultCallback: func(result *resolve.HostEntry) {
+				callbacks[result.Host]++
+			},
+		}
+		runner, err := NewRunner(options)
+		require.Nil(t, err)
+		require.Nil(t, runner.RunEnumeration())
+
+		var hosts []string
+		scanner := bufio.NewScanner(&output.Buffer)
+		for scanner.Scan() {
+			var result jsonSourceResult
+			require.Nil(t, jsoniter.Unmarshal(scanner.Bytes(), &result))
+			hosts = append(hosts, result.Host)
+		}
+		return hosts, callbacks
+	}
+
+	// Every host is written and passed to the callback once, as soon as it is found
+	hosts, callbacks := run(false)
+	assert.True(t, source.streamed, "host not written before the end of the enumeration")
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, hosts)
+	assert.Equal(t, map[string]int{"a.example.com": 1, "b.example.com": 1}, callbacks)
+
+	// The sources of a host are only complete at the end, when the output is written
+	hosts, callbacks = run(true)
+	assert.False(t, source.streamed, "host written before the end of the enumeration")
+	assert.ElementsMatch(t, []string{"a.example.com", "b.example.com"}, hosts)
+	assert.Equal(t, map[string]int{"a.example.com": 1, "b.example.com": 1}, callbacks)
+}
+
+func TestEnumerateMultipleDomainsConcurrently(t *testing.T) {
+	domains := []string{"a.com", "b.com", "c.com", "d.com"}
+	// The source only returns results once it runs against every domain at the same time
+	var started atomic.Int32
+	everyDomain := make(chan struct{})
+	registerSource(t, &staticSource{name: "concurrentsource", subdomains: func(domain string) []string {
+		if started.Add(1) == int32(len(domains)) {
+			close(everyDomain)
+		}
+		select {
+		case <-everyDomain:
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+		var subdomains []string
+		for i := 0; i < 50; i++ {
+			subdomains = append(subdomains, fmt.Sprintf("host%d.%s", i, domain))
+		}
+		return subdomains
+	}})
+
+	output := &bytes.Buffer{}
+	outputFile := filepath.Join(t.TempDir(), "output.json")
+	options := &Options{
+		Domain:             domains,
+		Sources:            []string{"concurrentsource"},
+		Threads:            2,
+		Timeout:            10,
+		MaxEnumerationTime: 1,
+		DomainConcurrency:  len(domains),
+		JSON:               true,
+		Output:             output,
+		OutputFile:         outputFile,
+		ProviderApiKeys:    map[string][]string{},
+	}
+	runner, err := NewRunner(options)
+	require.Nil(t, err)
+	require.Nil(t, runner.RunEnumeration())
+
+	var expected []string
+	for _, domain := range domains {
+		for i := 0; i < 50; i++ {
+			expected = append(expected, fmt.Sprintf("host%d.%s,%s", i, domain, domain))
+		}
+	}
+	// Every line written by the concurrent domains is whole, in the output as in the file appended to
+	content, err := os.ReadFile(outputFile)
+	require.Nil(t, err)
+	for _, written := range []*bytes.Buffer{output, bytes.NewBuffer(content)} {
+		var results []string
+		scanner := bufio.NewScanner(written)
+		for scanner.Scan() {
+			var result jsonSourceResult
+			require.Nil(t, jsoniter.Unmarshal(scanner.Bytes(), &result), scanner.Text())
+			results = append(results, result.Host+","+result.Input)
+		}
+		assert.ElementsMatch(t, expected, results)
+	}
+}
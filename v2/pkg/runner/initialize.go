@@ -2,13 +2,20 @@ package runner
 
 import (
 	"net"
+	"net/url"
 	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/passive"
 	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
 	"github.com/projectdiscovery/dnsx/libs/dnsx"
 )
 
+// resolverCheckTimeout is the timeout of the health check queries sent to each resolver
+const resolverCheckTimeout = 3 * time.Second
+
 // initializePassiveEngine creates the passive engine and loads sources etc
 func (r *Runner) initializePassiveEngine() {
 	r.passiveAgent = passive.New(r.options.Sources, r.options.ExcludeSources, r.options.All, r.options.OnlyRecursive)
@@ -40,12 +47,40 @@ func (r *Runner) initializeResolver() error {
 		}
 	}
 
+	recordTypes, err := resolve.RecordTypes(r.options.RecordTypes)
+	if err != nil {
+		return err
+	}
+
 	r.resolverClient = resolve.New()
-	var err error
-	r.resolverClient.DNSClient, err = dnsx.New(dnsx.Options{BaseResolvers: resolvers, MaxRetries: 5})
+	// The DoH resolvers are queried through the proxy of the sources
+	if r.options.Proxy != "" {
+		proxyURL, err := url.Parse(r.options.Proxy)
+		if err != nil {
+			gologger.Warning().Msgf("Invalid proxy provided: %s", r.options.Proxy)
+		}
+		r.resolverClient.Proxy = proxyURL
+	}
+	if r.options.ResolverCheckName != "" {
+		r.resolverClient.HealthCheckName = r.options.ResolverCheckName
+		r.resolverClient.HealthCheckIPs = r.options.ResolverCheckIPs
+	}
+	err = r.resolverClient.SetResolvers(resolvers, dnsx.Options{MaxRetries: 5, QuestionTypes: recordTypes})
 	if err != nil {
-		return nil
+		return err
 	}
 
+	// Prune the unhealthy resolvers before resolving anything
+	if r.resolvesSubdomains() && !r.options.NoResolverCheck {
+		gologger.Info().Msgf("Checking the health of %d resolvers", len(resolvers))
+		if err := r.resolverClient.CheckHealth(resolverCheckTimeout); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// resolvesSubdomains returns true if the options require resolving subdomains
+func (r *Runner) resolvesSubdomains() bool {
+	return r.options.RemoveWildcard || r.options.Wordlist != "" || r.options.Permute
+}
@@ -9,9 +9,12 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/passive"
 	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping/sources/custom"
 	"github.com/projectdiscovery/chaos-client/pkg/chaos"
 	"github.com/projectdiscovery/goflags"
 	"github.com/projectdiscovery/gologger"
@@ -24,6 +27,7 @@ var (
 	configDir                     = folderutil.AppConfigDirOrDefault(".", "subfinder")
 	defaultConfigLocation         = filepath.Join(configDir, "config.yaml")
 	defaultProviderConfigLocation = filepath.Join(configDir, "provider-config.yaml")
+	defaultCustomSourcesLocation  = filepath.Join(configDir, "custom-sources.yaml")
 )
 
 // Options contains the configuration options for tuning
@@ -36,15 +40,24 @@ type Options struct {
 	Silent             bool                // Silent suppresses any extra text and only writes subdomains to screen
 	ListSources        bool                // ListSources specifies whether to list all available sources
 	RemoveWildcard     bool                // RemoveWildcard specifies whether to remove potential wildcard or dead subdomains from the results.
+	LabelWildcard      bool                // LabelWildcard specifies whether to label wildcard subdomains instead of removing them
 	CaptureSources     bool                // CaptureSources specifies whether to save all sources that returned a specific domains or just the first source
+	Metadata           bool                // Metadata specifies whether to write the metadata returned by the sources in the json output
 	Stdin              bool                // Stdin specifies whether stdin input was given to the process
 	Version            bool                // Version specifies if we should just show version and exit
 	OnlyRecursive      bool                // Recursive specifies whether to use only recursive subdomain enumeration sources
+	RecursiveDepth     int                 // RecursiveDepth is the number of times the subdomains found are enumerated again with the recursive sources
+	RecursiveBreadth   int                 // RecursiveBreadth is the maximum number of subdomains enumerated recursively at each depth
 	All                bool                // All specifies whether to use all (slow) sources.
 	Statistics         bool                // Statistics specifies whether to report source statistics
+	Stream             bool                // Stream specifies whether to write results as soon as they are discovered
+	ResultDatabase     string              // ResultDatabase is the database file keeping track of the results of previous runs
+	NewOnly            bool                // NewOnly specifies whether to only output subdomains not found by previous runs
+	Resume             string              // Resume is the checkpoint file used to resume an interrupted enumeration
 	Threads            int                 // Threads controls the number of threads to use for active enumerations
 	Timeout            int                 // Timeout is the seconds to wait for sources to respond
 	MaxEnumerationTime int                 // MaxEnumerationTime is the maximum amount of time in minutes to wait for enumeration
+	DomainConcurrency  int                 // DomainConcurrency is the number of domains to enumerate concurrently
 	Domain             goflags.StringSlice // Domain is the domain to find subdomains for
 	DomainsFile        string              // DomainsFile is the file containing list of domains to find subdomains for
 	Output             io.Writer
@@ -55,16 +68,48 @@ type Options struct {
 	ExcludeSources     goflags.StringSlice  `yaml:"exclude-sources,omitempty"` // ExcludeSources contains the comma-separated sources to not include in the enumeration process
 	Resolvers          goflags.StringSlice  `yaml:"resolvers,omitempty"`       // Resolvers is the comma-separated resolvers to use for enumeration
 	ResolverList       string               // ResolverList is a text file containing list of resolvers to use for enumeration
+	RecordTypes        goflags.StringSlice  // RecordTypes contains the optional record types to collect in addition to A, AAAA and CNAME
+	PTRSweep           bool                 // PTRSweep specifies whether to query the PTR records of the netblocks of the resolved IPs
+	PTRNetblock        int                  // PTRNetblock is the prefix length of the netblocks swept for PTR records
+	PTRLimit           int                  // PTRLimit is the maximum number of PTR queries sent during the run
+	TLSProbe           bool                 // TLSProbe specifies whether to read the certificates served by the resolved hosts
+	TLSPorts           goflags.StringSlice  // TLSPorts contains the ports the resolved hosts are probed for certificates on
+	Probe              bool                 // Probe specifies whether to request the resolved hosts over HTTP and HTTPS
+	ProbeThreads       int                  // ProbeThreads is the number of hosts probed concurrently
+	ProbeTimeout       int                  // ProbeTimeout is the seconds to wait for a probed host to respond
+	Takeover           bool                 // Takeover specifies whether to check the CNAME targets of the resolved hosts for possible takeovers
+	TakeoverFile       string               // TakeoverFile is the YAML file of fingerprints extending the bundled takeover fingerprints
+	TakeoverHTTP       bool                 // TakeoverHTTP specifies whether to match the bodies served for the hosts against the takeover fingerprints
+	ASNDatabase        string               // ASNDatabase is the local IP-to-ASN database file the resolved IPs are mapped with
+	NoResolverCheck    bool                 // NoResolverCheck disables the health checks of the resolvers
+	ResolverInterval   time.Duration        // ResolverInterval is the interval between two health checks of the resolvers
+	ResolverCheckName  string               // ResolverCheckName is the name queried by the health checks of the resolvers
+	ResolverCheckIPs   goflags.StringSlice  // ResolverCheckIPs contains the addresses ResolverCheckName is expected to resolve to
+	Wordlist           string               // Wordlist is a text file containing the words to brute-force subdomains with
+	Permute            bool                 // Permute specifies whether to resolve permutations of the subdomains found
+	PermutationWords   string               // PermutationWords is a text file containing the words to alter the subdomains found with
+	PermutationLimit   int                  // PermutationLimit is the maximum number of permutations resolved per domain
+	ZoneEnum           bool                 // ZoneEnum specifies whether to try zone transfers and zone walking against the authoritative nameservers
+	NSEC3Output        string               // NSEC3Output is the file the NSEC3 hashes collected by zone walking are appended to
 	Config             string               // Config contains the location of the config file
 	ProviderConfig     string               // ProviderConfig contains the location of the provider config file
+	CustomSources      string               // CustomSources contains the location of the file declaring HTTP-JSON sources
+	ProviderApiKeys    map[string][]string  // ProviderApiKeys contains the API keys of the sources, used instead of the provider config file when set
 	Proxy              string               // HTTP proxy
 	RateLimit          int                  // Global maximum number of HTTP requests to send per second
 	RateLimits         goflags.RateLimitMap // Maximum number of HTTP requests to send per second
+	MaxPages           goflags.StringSlice  // MaxPages contains the maximum number of pages requested from the paged sources in key=value format
+	MaxResults         goflags.StringSlice  // MaxResults contains the number of results after which the paged sources stop in key=value format
+	Retries            int                  // Retries is the number of times a failed HTTP request of a source is sent again
+	RetryDelay         time.Duration        // RetryDelay is the backoff before the first retry of a request, doubled for every following one
+	SourceRetries      goflags.StringSlice  // SourceRetries contains the number of retries of the sources in key=value format
 	ExcludeIps         bool
 	Match              goflags.StringSlice
 	Filter             goflags.StringSlice
 	matchRegexes       []*regexp.Regexp
 	filterRegexes      []*regexp.Regexp
+	pageLimits         map[string]subscraping.PageLimits
+	retryPolicies      map[string]subscraping.RetryPolicy
 	ResultCallback     OnResultCallback // OnResult callback
 	DisableUpdateCheck bool             // DisableUpdateCheck disable update checking
 }
@@ -91,6 +136,8 @@ func ParseOptions() *Options {
 		flagSet.StringSliceVarP(&options.Sources, "sources", "s", nil, "specific sources to use for discovery (-s crtsh,github). Use -ls to display all available sources.", goflags.NormalizedStringSliceOptions),
 		flagSet.BoolVar(&options.OnlyRecursive, "recursive", false, "use only sources that can handle subdomains recursively rather than both recursive and non-recursive sources"),
 		flagSet.BoolVar(&options.All, "all", false, "use all sources for enumeration (slow)"),
+		flagSet.IntVarP(&options.RecursiveDepth, "recursive-depth", "rd", 0, "enumerate the subdomains found again with the recursive sources up to the given depth"),
+		flagSet.IntVarP(&options.RecursiveBreadth, "recursive-breadth", "rb", 10, "maximum number of subdomains to enumerate recursively at each depth"),
 		flagSet.StringSliceVarP(&options.ExcludeSources, "exclude-sources", "es", nil, "sources to exclude from enumeration (-es alienvault,zoomeyeapi)", goflags.NormalizedStringSliceOptions),
 	)
 
@@ -101,7 +148,12 @@ func ParseOptions() *Options {
 
 	flagSet.CreateGroup("rate-limit", "Rate-limit",
 		flagSet.IntVarP(&options.RateLimit, "rate-limit", "rl", 0, "maximum number of http requests to send per second (global)"),
-		flagSet.RateLimitMapVarP(&options.RateLimits, "rate-limits", "rls", defaultRateLimits, "maximum number of http requests to send per second for providers in key=value format (-rls hackertarget=10/m)", goflags.NormalizedStringSliceOptions),
+		flagSet.RateLimitMapVarP(&options.RateLimits, "rate-limits", "rls", DefaultRateLimits, "maximum number of http requests to send per second for providers in key=value format (-rls hackertarget=10/m)", goflags.NormalizedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.MaxPages, "max-pages", "mp", nil, "maximum number of pages requested from the paged providers in key=value format, hunter, quake and censys request 10 by default (-mp censys=5)", goflags.NormalizedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.MaxResults, "max-results", "mr", nil, "number of results after which the paged providers stop in key=value format (-mr hunter=500)", goflags.NormalizedStringSliceOptions),
+		flagSet.IntVar(&options.Retries, "retries", 2, "number of times a failed http request to a provider is retried"),
+		flagSet.DurationVarP(&options.RetryDelay, "retry-delay", "rtd", time.Second, "backoff before the first retry of a failed http request, doubled for every following one"),
+		flagSet.StringSliceVarP(&options.SourceRetries, "source-retries", "srt", nil, "number of retries for providers in key=value format (-srt virustotal=0)", goflags.NormalizedStringSliceOptions),
 		flagSet.IntVar(&options.Threads, "t", 10, "number of concurrent goroutines for resolving (-active only)"),
 	)
 
@@ -115,18 +167,48 @@ func ParseOptions() *Options {
 		flagSet.BoolVarP(&options.JSON, "json", "oJ", false, "write output in JSONL(ines) format"),
 		flagSet.StringVarP(&options.OutputDirectory, "output-dir", "oD", "", "directory to write output (-dL only)"),
 		flagSet.BoolVarP(&options.CaptureSources, "collect-sources", "cs", false, "include all sources in the output (-json only)"),
-		flagSet.BoolVarP(&options.HostIP, "ip", "oI", false, "include host IP in output (-active only)"),
+		flagSet.BoolVarP(&options.Metadata, "metadata", "md", false, "include the first/last seen, ips, ports and certificate fingerprints of every source in the output (-json only)"),
+		flagSet.BoolVarP(&options.HostIP, "ip", "oI", false, "include host IPs and dns records in output (-active only)"),
 		flagSet.StringVarP(&options.RespFileDirectory, "resp-dir", "oR", "", "directory to write response files (-oR only)"),
+		flagSet.BoolVar(&options.Stream, "stream", false, "write results as soon as they are discovered"),
+		flagSet.StringVar(&options.ResultDatabase, "db", "", "database file to track subdomains found across runs"),
+		flagSet.BoolVarP(&options.NewOnly, "new-only", "no", false, "only output subdomains not found by previous runs (-db only)"),
 	)
 
 	flagSet.CreateGroup("configuration", "Configuration",
 		flagSet.StringVar(&options.Config, "config", defaultConfigLocation, "flag config file"),
 		flagSet.StringVarP(&options.ProviderConfig, "provider-config", "pc", defaultProviderConfigLocation, "provider config file"),
-		flagSet.StringSliceVar(&options.Resolvers, "r", nil, "comma separated list of resolvers to use", goflags.NormalizedStringSliceOptions),
+		flagSet.StringVarP(&options.CustomSources, "custom-sources", "csf", defaultCustomSourcesLocation, "file declaring custom http-json and plugin sources"),
+		flagSet.StringSliceVar(&options.Resolvers, "r", nil, "comma separated list of resolvers to use (ip:port, https://host/dns-query for DoH, tls://host:853 for DoT)", goflags.NormalizedStringSliceOptions),
 		flagSet.StringVarP(&options.ResolverList, "rlist", "rL", "", "file containing list of resolvers to use"),
+		flagSet.BoolVarP(&options.NoResolverCheck, "disable-resolver-check", "drc", false, "disable the health checks pruning bad resolvers"),
+		flagSet.DurationVarP(&options.ResolverInterval, "resolver-check-interval", "rci", 5*time.Minute, "interval between health checks of the resolvers (0 to only check at startup)"),
+		flagSet.StringVarP(&options.ResolverCheckName, "resolver-check-name", "rcn", resolve.DefaultHealthCheckName, "name queried by the health checks of the resolvers"),
+		flagSet.StringSliceVarP(&options.ResolverCheckIPs, "resolver-check-ips", "rcip", resolve.DefaultHealthCheckIPs, "comma separated addresses the resolver check name must resolve to", goflags.NormalizedStringSliceOptions),
 		flagSet.BoolVarP(&options.RemoveWildcard, "active", "nW", false, "display active subdomains only"),
+		flagSet.BoolVarP(&options.LabelWildcard, "label-wildcard", "lw", false, "label wildcard subdomains in json output instead of removing them (-active only)"),
+		flagSet.StringVarP(&options.Wordlist, "wordlist", "w", "", "file containing words to brute-force subdomains with"),
+		flagSet.BoolVarP(&options.Permute, "permute", "pm", false, "resolve permutations of the subdomains found"),
+		flagSet.StringVarP(&options.PermutationWords, "permutation-words", "pw", "", "file containing words to alter the subdomains found with (-permute only)"),
+		flagSet.IntVarP(&options.PermutationLimit, "permutation-limit", "pl", 10000, "maximum number of permutations to resolve per domain (-permute only)"),
+		flagSet.BoolVarP(&options.ZoneEnum, "zone-enum", "ze", false, "try zone transfers (axfr) and nsec zone walking against the authoritative nameservers"),
+		flagSet.StringVarP(&options.NSEC3Output, "nsec3-output", "n3o", "", "file to append the nsec3 hashes collected by zone walking to, in hashcat format (-zone-enum only)"),
+		flagSet.StringSliceVarP(&options.RecordTypes, "record-type", "rt", nil, "additional dns records to collect for active subdomains (mx,ns,txt)", goflags.NormalizedStringSliceOptions),
+		flagSet.BoolVarP(&options.PTRSweep, "ptr-sweep", "ptr", false, "query the ptr records of the netblocks of the resolved ips for more subdomains (-active only)"),
+		flagSet.IntVarP(&options.PTRNetblock, "ptr-netblock", "pnb", 24, "prefix length of the ipv4 netblocks swept for ptr records (16-32)"),
+		flagSet.IntVarP(&options.PTRLimit, "ptr-limit", "ptl", 65536, "maximum number of ptr queries sent during the run"),
+		flagSet.BoolVarP(&options.TLSProbe, "tls-probe", "tlp", false, "read the certificates served by the resolved hosts for more subdomains (-active only)"),
+		flagSet.StringSliceVarP(&options.TLSPorts, "tls-ports", "tpo", []string{"443", "8443"}, "comma separated list of ports probed for certificates (-tls-probe only)", goflags.NormalizedStringSliceOptions),
+		flagSet.BoolVar(&options.Probe, "probe", false, "request the resolved hosts over http and https, adding status, title and server to the json output (-active only)"),
+		flagSet.IntVarP(&options.ProbeThreads, "probe-threads", "pt", 25, "number of hosts probed concurrently (-probe only)"),
+		flagSet.IntVarP(&options.ProbeTimeout, "probe-timeout", "pto", 10, "seconds to wait for a probed host to respond (-probe only)"),
+		flagSet.BoolVarP(&options.Takeover, "takeover", "to", false, "flag the resolved hosts whose cname targets could be taken over (-active only)"),
+		flagSet.StringVarP(&options.TakeoverFile, "takeover-fingerprints", "tof", "", "yaml file of fingerprints extending the bundled takeover fingerprints (-takeover only)"),
+		flagSet.BoolVarP(&options.TakeoverHTTP, "takeover-http", "toh", false, "match the body served for the hosts against the takeover fingerprints (-takeover only)"),
+		flagSet.StringVar(&options.ASNDatabase, "asn-db", "", "local ip-to-asn database (iptoasn tsv or mmdb) to map the resolved ips with (-active only)"),
 		flagSet.StringVar(&options.Proxy, "proxy", "", "http proxy to use with subfinder"),
 		flagSet.BoolVarP(&options.ExcludeIps, "exclude-ip", "ei", false, "exclude IPs from the list of domains"),
+		flagSet.StringVar(&options.Resume, "resume", "", "checkpoint file to resume an interrupted enumeration from"),
 	)
 
 	flagSet.CreateGroup("debug", "Debug",
@@ -141,6 +223,7 @@ func ParseOptions() *Options {
 	flagSet.CreateGroup("optimization", "Optimization",
 		flagSet.IntVar(&options.Timeout, "timeout", 30, "seconds to wait before timing out"),
 		flagSet.IntVar(&options.MaxEnumerationTime, "max-time", 10, "minutes to wait for enumeration results"),
+		flagSet.IntVarP(&options.DomainConcurrency, "domain-concurrency", "dc", 1, "number of domains to enumerate concurrently"),
 	)
 
 	if err := flagSet.Parse(); err != nil {
@@ -176,8 +259,6 @@ func ParseOptions() *Options {
 		os.Exit(0)
 	}
 
-	options.preProcessDomains()
-
 	options.ConfigureOutput()
 	showBanner()
 
@@ -193,6 +274,10 @@ func ParseOptions() *Options {
 		//}
 	}
 
+	if err := options.loadCustomSources(); err != nil {
+		gologger.Fatal().Msgf("Could not load custom sources from %s: %s\n", options.CustomSources, err)
+	}
+
 	if options.ListSources {
 		listSources(options)
 		os.Exit(0)
@@ -200,7 +285,7 @@ func ParseOptions() *Options {
 
 	// Validate the options passed by the user and if any
 	// invalid options have been used, exit.
-	err = options.validateOptions()
+	err = options.Validate()
 	if err != nil {
 		gologger.Fatal().Msgf("Program exiting: %s\n", err)
 	}
@@ -208,26 +293,61 @@ func ParseOptions() *Options {
 	return options
 }
 
-// loadProvidersFrom runs the app with source config
-func (options *Options) loadProvidersFrom(location string) {
+// loadProvidersFrom loads the API keys of the provider config into the passive agent of the runner
+func (r *Runner) loadProvidersFrom(location string) {
 	// todo: move elsewhere
-	if len(options.Resolvers) == 0 {
-		options.Resolvers = resolve.DefaultResolvers
+	if len(r.options.Resolvers) == 0 {
+		r.options.Resolvers = resolve.DefaultResolvers
 	}
 
 	// We skip bailing out if file doesn't exist because we'll create it
 	// at the end of options parsing from default via goflags.
-	if err := UnmarshalFrom(location); err != nil && (!strings.Contains(err.Error(), "file doesn't exist") || errors.Is(err, os.ErrNotExist)) {
+	sourceApiKeysMap, err := UnmarshalFrom(location)
+	if err != nil && (!strings.Contains(err.Error(), "file doesn't exist") || errors.Is(err, os.ErrNotExist)) {
 		gologger.Error().Msgf("Could not read providers from %s: %s\n", location, err)
 	}
+	r.passiveAgent.AddApiKeys(sourceApiKeysMap)
+}
+
+// loadCustomSources registers the sources declared in the custom sources file.
+// Their rate limit applies unless the -rls flag sets one.
+func (options *Options) loadCustomSources() error {
+	return LoadCustomSources(options.CustomSources, &options.RateLimits)
+}
+
+// LoadCustomSources registers the sources declared in the custom sources file at the
+// location, which may be missing at its default location. The rate limit of each source
+// is added to the rate limits unless these already hold one for the source.
+func LoadCustomSources(location string, rateLimits *goflags.RateLimitMap) error {
+	if location == "" || (location == defaultCustomSourcesLocation && !fileutil.FileExists(location)) {
+		return nil
+	}
+	definitions, err := custom.Load(location)
+	if err != nil {
+		return err
+	}
+	for _, definition := range definitions {
+		definition := definition
+		if err := passive.RegisterSource(func() subscraping.Source { return custom.New(definition) }); err != nil {
+			return err
+		}
+		if _, ok := rateLimits.AsMap()[definition.Name]; definition.RateLimit != "" && !ok {
+			if err := rateLimits.Set(definition.Name + "=" + definition.RateLimit); err != nil {
+				return fmt.Errorf("invalid rate limit of %s: %s", definition.Name, err)
+			}
+		}
+	}
+	gologger.Info().Msgf("Loaded %d custom sources from %s", len(definitions), location)
+	return nil
 }
 
 func listSources(options *Options) {
-	gologger.Info().Msgf("Current list of available sources. [%d]\n", len(passive.AllSources))
+	allSources := passive.NewSources()
+	gologger.Info().Msgf("Current list of available sources. [%d]\n", len(allSources))
 	gologger.Info().Msgf("Sources marked with an * need key(s) or token(s) to work.\n")
 	gologger.Info().Msgf("You can modify %s to configure your keys/tokens.\n\n", options.ProviderConfig)
 
-	for _, source := range passive.AllSources {
+	for _, source := range allSources {
 		message := "%s\n"
 		sourceName := source.Name()
 		if source.NeedsKey() {
@@ -237,13 +357,15 @@ func listSources(options *Options) {
 	}
 }
 
+// preProcessDomains normalizes the domains as the domains read from a list are
 func (options *Options) preProcessDomains() {
 	for i, domain := range options.Domain {
-		options.Domain[i] = preprocessDomain(domain)
+		options.Domain[i] = replacer.Replace(preprocessDomain(domain))
 	}
 }
 
-var defaultRateLimits = []string{
+// DefaultRateLimits contains the default rate limits of the sources in key=value format
+var DefaultRateLimits = []string{
 	"github=30/m",
 	"fullhunt=60/m",
 	fmt.Sprintf("robtex=%d/ms", uint(math.MaxUint)),
@@ -260,6 +382,6 @@ var defaultRateLimits = []string{
 	// "gitlab=2/s",
 	"github=83/m",
 	"hudsonrock=5/s",
-	"hunter=5/s",
-	"quake=5/s",
+	"hunter=12/m",
+	"quake=6/m",
 }
@@ -7,10 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
+	"github.com/YouChenJun/subfinder-plus/pkg/asn"
+	"github.com/YouChenJun/subfinder-plus/pkg/probe"
 	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
 
 // OutputWriter outputs content to writers.
@@ -19,16 +23,99 @@ type OutputWriter struct {
 }
 
 type jsonSourceResult struct {
-	Host   string `json:"host"`
-	Input  string `json:"input"`
-	Source string `json:"source"`
+	Host     string      `json:"host"`
+	Input    string      `json:"input"`
+	Source   string      `json:"source"`
+	Parent   string      `json:"parent,omitempty"`
+	Wildcard string      `json:"wildcard,omitempty"`
+	ASN      []jsonASN   `json:"asn,omitempty"`
+	Probe    []jsonProbe `json:"probe,omitempty"`
+	jsonTakeover
+	jsonRecords
 }
 
 type jsonSourceIPResult struct {
-	Host   string `json:"host"`
-	IP     string `json:"ip"`
-	Input  string `json:"input"`
-	Source string `json:"source"`
+	Host     string      `json:"host"`
+	IP       string      `json:"ip"`
+	Input    string      `json:"input"`
+	Source   string      `json:"source"`
+	Parent   string      `json:"parent,omitempty"`
+	Wildcard string      `json:"wildcard,omitempty"`
+	ASN      []jsonASN   `json:"asn,omitempty"`
+	Probe    []jsonProbe `json:"probe,omitempty"`
+	jsonTakeover
+	jsonRecords
+}
+
+type jsonRecords struct {
+	A     []string `json:"a,omitempty"`
+	AAAA  []string `json:"aaaa,omitempty"`
+	CNAME []string `json:"cname,omitempty"`
+	MX    []string `json:"mx,omitempty"`
+	NS    []string `json:"ns,omitempty"`
+	TXT   []string `json:"txt,omitempty"`
+}
+
+func newJSONRecords(records resolve.Records) jsonRecords {
+	return jsonRecords{A: records.A, AAAA: records.AAAA, CNAME: records.CNAME, MX: records.MX, NS: records.NS, TXT: records.TXT}
+}
+
+// jsonASN is the autonomous system an IP address of a host belongs to
+type jsonASN struct {
+	IP      string `json:"ip"`
+	Number  uint32 `json:"as_number"`
+	Name    string `json:"as_name,omitempty"`
+	Country string `json:"as_country,omitempty"`
+	CIDR    string `json:"as_range,omitempty"`
+}
+
+func newJSONASN(infos []asn.Info) []jsonASN {
+	if len(infos) == 0 {
+		return nil
+	}
+	data := make([]jsonASN, 0, len(infos))
+	for _, info := range infos {
+		data = append(data, jsonASN{IP: info.IP, Number: info.Number, Name: info.Name, Country: info.Country, CIDR: info.CIDR})
+	}
+	return data
+}
+
+// jsonProbe is the response of a host to an HTTP probe request
+type jsonProbe struct {
+	URL           string `json:"url"`
+	StatusCode    int    `json:"status_code"`
+	Title         string `json:"title,omitempty"`
+	ContentLength int64  `json:"content_length"`
+	FinalURL      string `json:"final_url"`
+	Server        string `json:"server,omitempty"`
+}
+
+func newJSONProbe(responses []probe.Response) []jsonProbe {
+	if len(responses) == 0 {
+		return nil
+	}
+	data := make([]jsonProbe, 0, len(responses))
+	for _, response := range responses {
+		data = append(data, jsonProbe{
+			URL:           response.URL,
+			StatusCode:    response.StatusCode,
+			Title:         response.Title,
+			ContentLength: response.ContentLength,
+			FinalURL:      response.FinalURL,
+			Server:        response.Server,
+		})
+	}
+	return data
+}
+
+// jsonTakeover flags a host whose CNAME target could be claimed on a third-party service
+type jsonTakeover struct {
+	Candidate bool   `json:"takeover_candidate,omitempty"`
+	Service   string `json:"takeover_service,omitempty"`
+}
+
+func newJSONTakeover(service string) jsonTakeover {
+	return jsonTakeover{Candidate: service != "", Service: service}
 }
 
 type jsonSourcesResult struct {
@@ -37,6 +124,40 @@ type jsonSourcesResult struct {
 	Sources []string `json:"sources"`
 }
 
+// jsonMetadataResult is a subdomain along with the metadata of every source
+// which found it, merged across sources at the top level
+type jsonMetadataResult struct {
+	Host     string      `json:"host"`
+	IP       string      `json:"ip,omitempty"`
+	Wildcard string      `json:"wildcard,omitempty"`
+	ASN      []jsonASN   `json:"asn,omitempty"`
+	Probe    []jsonProbe `json:"probe,omitempty"`
+	jsonTakeover
+	*jsonRecords
+	jsonMetadata
+	Input   string                  `json:"input"`
+	Sources map[string]jsonMetadata `json:"sources"`
+}
+
+type jsonMetadata struct {
+	FirstSeen    *time.Time `json:"first_seen,omitempty"`
+	LastSeen     *time.Time `json:"last_seen,omitempty"`
+	IPs          []string   `json:"ips,omitempty"`
+	Ports        []int      `json:"ports,omitempty"`
+	Fingerprints []string   `json:"fingerprints,omitempty"`
+}
+
+func newJSONMetadata(metadata *subscraping.Metadata) jsonMetadata {
+	data := jsonMetadata{IPs: metadata.IPs, Ports: metadata.Ports, Fingerprints: metadata.Fingerprints}
+	if !metadata.FirstSeen.IsZero() {
+		data.FirstSeen = &metadata.FirstSeen
+	}
+	if !metadata.LastSeen.IsZero() {
+		data.LastSeen = &metadata.LastSeen
+	}
+	return data
+}
+
 // NewOutputWriter creates a new OutputWriter
 func NewOutputWriter(json bool) *OutputWriter {
 	return &OutputWriter{JSON: json}
@@ -88,9 +209,13 @@ func writePlainHostIP(_ string, results map[string]resolve.Result, writer io.Wri
 	sb := &strings.Builder{}
 
 	for _, result := range results {
+		ips := result.Records.IPs()
+		if len(ips) == 0 {
+			ips = []string{result.IP}
+		}
 		sb.WriteString(result.Host)
 		sb.WriteString(",")
-		sb.WriteString(result.IP)
+		sb.WriteString(strings.Join(ips, "|"))
 		sb.WriteString(",")
 		sb.WriteString(result.Source)
 		sb.WriteString("\n")
@@ -115,6 +240,12 @@ func writeJSONHostIP(input string, results map[string]resolve.Result, writer io.
 		data.IP = result.IP
 		data.Input = input
 		data.Source = result.Source
+		data.Parent = result.Parent
+		data.Wildcard = result.Wildcard
+		data.ASN = newJSONASN(result.ASN)
+		data.Probe = newJSONProbe(result.Probe)
+		data.jsonTakeover = newJSONTakeover(result.Takeover)
+		data.jsonRecords = newJSONRecords(result.Records)
 
 		err := encoder.Encode(&data)
 		if err != nil {
@@ -126,12 +257,37 @@ func writeJSONHostIP(input string, results map[string]resolve.Result, writer io.
 
 // WriteHostNoWildcard writes the output list of subdomain with nW flag to an io.Writer
 func (o *OutputWriter) WriteHostNoWildcard(input string, results map[string]resolve.Result, writer io.Writer) error {
+	if o.JSON {
+		return writeJSONHostNoWildcard(input, results, writer)
+	}
+
 	hosts := make(map[string]resolve.HostEntry)
 	for host, result := range results {
-		hosts[host] = resolve.HostEntry{Domain: host, Host: result.Host, Source: result.Source}
+		hosts[host] = resolve.HostEntry{Domain: host, Host: result.Host, Source: result.Source, Parent: result.Parent}
 	}
+	return writePlainHost(input, hosts, writer)
+}
+
+func writeJSONHostNoWildcard(input string, results map[string]resolve.Result, writer io.Writer) error {
+	encoder := jsoniter.NewEncoder(writer)
 
-	return o.WriteHost(input, hosts, writer)
+	var data jsonSourceResult
+	for _, result := range results {
+		data.Host = result.Host
+		data.Input = input
+		data.Source = result.Source
+		data.Parent = result.Parent
+		data.Wildcard = result.Wildcard
+		data.ASN = newJSONASN(result.ASN)
+		data.Probe = newJSONProbe(result.Probe)
+		data.jsonTakeover = newJSONTakeover(result.Takeover)
+		data.jsonRecords = newJSONRecords(result.Records)
+		err := encoder.Encode(data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // WriteHost writes the output list of subdomain to an io.Writer
@@ -171,6 +327,7 @@ func writeJSONHost(input string, results map[string]resolve.HostEntry, writer io
 		data.Host = result.Host
 		data.Input = input
 		data.Source = result.Source
+		data.Parent = result.Parent
 		err := encoder.Encode(data)
 		if err != nil {
 			return err
@@ -235,3 +392,39 @@ func writeSourcePlainHost(_ string, sourceMap map[string]map[string]struct{}, wr
 	}
 	return bufwriter.Flush()
 }
+
+// WriteHostMetadata writes the output list of subdomain along with the metadata
+// of their sources to an io.Writer. When resolved results are given, only the
+// resolved subdomains are written along with their IP.
+func (o *OutputWriter) WriteHostMetadata(input string, metadataMap map[string]map[string]*subscraping.Metadata, resolved map[string]resolve.Result, writer io.Writer) error {
+	encoder := jsoniter.NewEncoder(writer)
+
+	for host, sources := range metadataMap {
+		data := jsonMetadataResult{Host: host, Input: input, Sources: make(map[string]jsonMetadata, len(sources))}
+		if resolved != nil {
+			result, ok := resolved[host]
+			if !ok {
+				continue
+			}
+			records := newJSONRecords(result.Records)
+			data.IP = result.IP
+			data.Wildcard = result.Wildcard
+			data.ASN = newJSONASN(result.ASN)
+			data.Probe = newJSONProbe(result.Probe)
+			data.jsonTakeover = newJSONTakeover(result.Takeover)
+			data.jsonRecords = &records
+		}
+
+		merged := &subscraping.Metadata{}
+		for source, metadata := range sources {
+			merged.Merge(metadata)
+			data.Sources[source] = newJSONMetadata(metadata)
+		}
+		data.jsonMetadata = newJSONMetadata(merged)
+
+		if err := encoder.Encode(&data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
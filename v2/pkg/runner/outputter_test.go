@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+)
+
+func TestWriteJSONRecords(t *testing.T) {
+	results := map[string]resolve.Result{
+		"www.example.com": {
+			Host:   "www.example.com",
+			IP:     "192.0.2.1",
+			Source: "crtsh",
+			Records: resolve.Records{
+				A:     []string{"192.0.2.1"},
+				AAAA:  []string{"2001:db8::1"},
+				CNAME: []string{"edge.example.net", "lb.example.net"},
+			},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	require.Nil(t, NewOutputWriter(true).WriteHostIP("example.com", results, output))
+
+	var data map[string]interface{}
+	require.Nil(t, jsoniter.Unmarshal(output.Bytes(), &data))
+	assert.Equal(t, "www.example.com", data["host"])
+	assert.Equal(t, "192.0.2.1", data["ip"])
+	assert.Equal(t, []interface{}{"192.0.2.1"}, data["a"])
+	assert.Equal(t, []interface{}{"2001:db8::1"}, data["aaaa"])
+	assert.Equal(t, []interface{}{"edge.example.net", "lb.example.net"}, data["cname"])
+	// The record types which were not queried are left out
+	assert.NotContains(t, data, "mx")
+	assert.NotContains(t, data, "ns")
+	assert.NotContains(t, data, "txt")
+
+	// The JSON output of the resolved hosts without their IP carries the records as well
+	output.Reset()
+	require.Nil(t, NewOutputWriter(true).WriteHostNoWildcard("example.com", results, output))
+	data = nil
+	require.Nil(t, jsoniter.Unmarshal(output.Bytes(), &data))
+	assert.Equal(t, []interface{}{"192.0.2.1"}, data["a"])
+	assert.Equal(t, []interface{}{"edge.example.net", "lb.example.net"}, data["cname"])
+}
@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/permutation"
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// permutationSource is the source of the subdomains found by resolving permutations of the subdomains found
+const permutationSource = "permutation"
+
+// permute resolves the permutations of the hosts found under the domain and returns
+// the permutations which resolve to non wildcard answers as results
+func (r *Runner) permute(ctx context.Context, domain string, hosts []string, wildcards *resolve.WildcardCache, resolved *resolvedRecords, statistics *subscraping.StatisticsCollector) <-chan subscraping.Result {
+	candidates := permutation.Generate(domain, hosts, r.permutationWords, r.options.PermutationLimit)
+	gologger.Info().Msgf("Resolving %d permutations of the subdomains of %s\n", len(candidates), domain)
+	return r.resolveCandidates(ctx, domain, permutationSource, candidates, wildcards, resolved, statistics)
+}
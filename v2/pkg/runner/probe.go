@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/probe"
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// probeSource is the source of the subdomains the HTTP probe requests were redirected to
+const probeSource = "probe"
+
+// probeResolved requests the resolved hosts over HTTP and HTTPS and records
+// their responses. The hosts in scope the requests were redirected to are
+// resolved and probed in turn.
+func (r *Runner) probeResolved(ctx context.Context, domain string, foundResults map[string]resolve.Result, resolveResults func(<-chan subscraping.Result), statistics *subscraping.StatisticsCollector) {
+	session, err := subscraping.NewSession(domain, r.options.Proxy, nil, r.options.ProbeTimeout, "")
+	if err != nil {
+		gologger.Warning().Msgf("Could not create session to probe %s: %s\n", domain, err)
+		return
+	}
+	defer session.Close()
+	statistics.AddSource(probeSource)
+
+	probed := make(map[string]struct{})
+	for ctx.Err() == nil {
+		var hosts []string
+		for host, result := range foundResults {
+			if _, ok := probed[host]; ok || result.Wildcard != "" {
+				continue
+			}
+			probed[host] = struct{}{}
+			hosts = append(hosts, host)
+		}
+		if len(hosts) == 0 {
+			break
+		}
+		sort.Strings(hosts)
+
+		responses := make(map[string][]probe.Response)
+		resolveResults(r.probeHTTP(ctx, session, domain, hosts, responses, statistics))
+		for host, hostResponses := range responses {
+			result := foundResults[host]
+			result.Probe = hostResponses
+			foundResults[host] = result
+		}
+	}
+}
+
+// probeHTTP requests every host and records its responses in the map, which
+// must not be read before the results are drained. The hosts in scope of the
+// domain the requests were redirected to are returned as results.
+func (r *Runner) probeHTTP(ctx context.Context, session *subscraping.Session, domain string, hosts []string, responses map[string][]probe.Response, statistics *subscraping.StatisticsCollector) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	gologger.Info().Msgf("Probing %d hosts of %s over HTTP\n", len(hosts), domain)
+	startTime := time.Now()
+
+	targets := make(chan string)
+	go func() {
+		defer close(targets)
+		for _, host := range hosts {
+			select {
+			case <-ctx.Done():
+				return
+			case targets <- host:
+			}
+		}
+	}()
+
+	var mutex sync.Mutex
+	seen := make(map[string]struct{})
+	wg := &sync.WaitGroup{}
+	for i := 0; i < r.options.ProbeThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range targets {
+				hostResponses := probe.Host(ctx, session.Client, host)
+				mutex.Lock()
+				responses[host] = hostResponses
+				mutex.Unlock()
+
+				for _, response := range hostResponses {
+					finalURL, err := url.Parse(response.FinalURL)
+					if err != nil {
+						continue
+					}
+					name := strings.ToLower(finalURL.Hostname())
+					if name == host || !strings.HasSuffix(name, "."+domain) {
+						continue
+					}
+					mutex.Lock()
+					_, ok := seen[name]
+					seen[name] = struct{}{}
+					mutex.Unlock()
+					if ok {
+						continue
+					}
+					statistics.AddResult(probeSource)
+					results <- subscraping.Result{Type: subscraping.Subdomain, Source: probeSource, Value: name}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		statistics.AddTimeTaken(probeSource, time.Since(startTime))
+		close(results)
+	}()
+	return results
+}
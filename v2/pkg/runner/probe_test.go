@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/probe"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+func TestProbeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Host {
+		case "a.example.com":
+			http.Redirect(w, req, "http://b.example.com/", http.StatusMovedPermanently)
+		case "c.example.com":
+			http.Redirect(w, req, "http://example.org/", http.StatusMovedPermanently)
+		}
+	}))
+	defer server.Close()
+
+	// Every host is served by the test server
+	session, err := subscraping.NewSession("example.com", "", nil, 5, "")
+	require.Nil(t, err)
+	session.Client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	defer session.Close()
+
+	runner := &Runner{options: &Options{ProbeThreads: 2}}
+	statistics := subscraping.NewStatisticsCollector()
+	responses := make(map[string][]probe.Response)
+
+	var names []string
+	for result := range runner.probeHTTP(context.Background(), session, "example.com", []string{"a.example.com", "c.example.com"}, responses, statistics) {
+		assert.Equal(t, probeSource, result.Source)
+		names = append(names, result.Value)
+	}
+
+	assert.Equal(t, []string{"b.example.com"}, names)
+	require.Len(t, responses["a.example.com"], 1)
+	assert.Equal(t, "http://b.example.com/", responses["a.example.com"][0].FinalURL)
+	require.Len(t, responses["c.example.com"], 1)
+	assert.Equal(t, "http://example.org/", responses["c.example.com"][0].FinalURL)
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"context"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// ptrSource is the source of the subdomains found in the PTR records of the netblocks of the resolved IPs
+const ptrSource = "ptr"
+
+// ptrNetblocks returns the IPv4 netblocks of the given size containing the
+// addresses of the resolved hosts, leaving out the wildcard subdomains
+func ptrNetblocks(results map[string]resolve.Result, bits int) []netip.Prefix {
+	seen := make(map[netip.Prefix]struct{})
+	var netblocks []netip.Prefix
+	for _, result := range results {
+		if result.Wildcard != "" {
+			continue
+		}
+		for _, ip := range result.Records.A {
+			addr, err := netip.ParseAddr(ip)
+			if err != nil || !addr.Is4() {
+				continue
+			}
+			netblock, _ := addr.Prefix(bits)
+			if _, ok := seen[netblock]; ok {
+				continue
+			}
+			seen[netblock] = struct{}{}
+			netblocks = append(netblocks, netblock)
+		}
+	}
+	sort.Slice(netblocks, func(i, j int) bool {
+		return netblocks[i].Addr().Less(netblocks[j].Addr())
+	})
+	return netblocks
+}
+
+// sweepPTR queries the PTR records of every address of the netblocks and returns
+// the names in scope of the domain as results. The queries are counted against
+// the limit shared by all the domains of the run.
+func (r *Runner) sweepPTR(ctx context.Context, domain string, netblocks []netip.Prefix, statistics *subscraping.StatisticsCollector) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	if len(netblocks) == 0 {
+		close(results)
+		return results
+	}
+
+	gologger.Info().Msgf("Sweeping %d netblocks of %s for PTR records\n", len(netblocks), domain)
+	startTime := time.Now()
+	statistics.AddSource(ptrSource)
+
+	addresses := make(chan netip.Addr)
+	go func() {
+		defer close(addresses)
+		for _, netblock := range netblocks {
+			for addr := netblock.Addr(); netblock.Contains(addr); addr = addr.Next() {
+				if r.ptrQueries.Add(1) > int64(r.options.PTRLimit) {
+					gologger.Warning().Msgf("PTR query limit of %d reached, stopping the sweep of %s\n", r.options.PTRLimit, domain)
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case addresses <- addr:
+				}
+			}
+		}
+	}()
+
+	var mutex sync.Mutex
+	seen := make(map[string]struct{})
+	wg := &sync.WaitGroup{}
+	for i := 0; i < r.options.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for addr := range addresses {
+				data, err := r.resolverClient.QueryTypes(addr.String(), dns.TypePTR)
+				if err != nil {
+					statistics.AddError(ptrSource)
+					continue
+				}
+				for _, name := range data.PTR {
+					name = strings.ToLower(strings.TrimSuffix(name, "."))
+					if !strings.HasSuffix(name, "."+domain) {
+						continue
+					}
+					mutex.Lock()
+					_, ok := seen[name]
+					seen[name] = struct{}{}
+					mutex.Unlock()
+					if ok {
+						continue
+					}
+					statistics.AddResult(ptrSource)
+					results <- subscraping.Result{Type: subscraping.Subdomain, Source: ptrSource, Value: name}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		statistics.AddTimeTaken(ptrSource, time.Since(startTime))
+		close(results)
+	}()
+	return results
+}
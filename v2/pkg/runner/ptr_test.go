@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+)
+
+func TestPTRNetblocks(t *testing.T) {
+	results := map[string]resolve.Result{
+		"a.example.com": {Records: resolve.Records{A: []string{"192.0.2.10", "198.51.100.1"}, AAAA: []string{"2001:db8::1"}}},
+		"b.example.com": {Records: resolve.Records{A: []string{"192.0.2.200"}}},
+		"c.example.com": {Records: resolve.Records{A: []string{"203.0.113.5"}}, Wildcard: "example.com"},
+	}
+
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/24"),
+		netip.MustParsePrefix("198.51.100.0/24"),
+	}, ptrNetblocks(results, 24))
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/28"),
+		netip.MustParsePrefix("192.0.2.192/28"),
+		netip.MustParsePrefix("198.51.100.0/28"),
+	}, ptrNetblocks(results, 28))
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/passive"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// recursiveConcurrency is the number of subdomains enumerated recursively at the same time
+const recursiveConcurrency = 5
+
+// targetResult is a result of a source run against a subdomain enumerated recursively
+type targetResult struct {
+	subscraping.Result
+	target string
+}
+
+// recursiveTargets returns the subdomains to enumerate recursively among the
+// hosts found at the previous depth. The intermediate names, i.e. the parents
+// of the hosts found, come first since they are the most likely to have
+// subdomains of their own. At most breadth targets are returned and every
+// target is recorded as enumerated.
+func recursiveTargets(domain string, hosts []string, enumerated map[string]struct{}, breadth int) []string {
+	var targets []string
+	addTarget := func(target string) {
+		if _, ok := enumerated[target]; ok || len(targets) >= breadth {
+			return
+		}
+		enumerated[target] = struct{}{}
+		targets = append(targets, target)
+	}
+
+	for _, host := range hosts {
+		subdomain, ok := strings.CutSuffix(host, "."+domain)
+		if !ok {
+			continue
+		}
+		labels := strings.Split(subdomain, ".")
+		for i := len(labels) - 1; i > 0; i-- {
+			addTarget(strings.Join(labels[i:], ".") + "." + domain)
+		}
+	}
+	for _, host := range hosts {
+		addTarget(host)
+	}
+	return targets
+}
+
+// enumerateTargets runs the passive enumeration against the targets, at most
+// recursiveConcurrency of them at a time, tagging each result with the target
+// which produced it. The enumeration of every target ends with the context.
+func (r *Runner) enumerateTargets(ctx context.Context, targets []string, options []passive.EnumerateOption) <-chan targetResult {
+	results := make(chan targetResult)
+
+	queue := make(chan string)
+	go func() {
+		defer close(queue)
+		for _, target := range targets {
+			select {
+			case <-ctx.Done():
+				return
+			case queue <- target:
+			}
+		}
+	}()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < min(recursiveConcurrency, len(targets)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				// The sources of the target get the time left before the deadline of the context
+				maxEnumerationTime := time.Duration(r.options.MaxEnumerationTime) * time.Minute
+				if deadline, ok := ctx.Deadline(); ok {
+					maxEnumerationTime = time.Until(deadline)
+				}
+				for result := range r.passiveAgent.EnumerateSubdomainsWithCtx(ctx, target, r.options.Proxy, r.options.RateLimit, r.options.Timeout, maxEnumerationTime, r.options.RespFileDirectory, options...) {
+					results <- targetResult{Result: result, target: target}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/passive"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// recursiveSource returns a fixed list of subdomains for each target
+type recursiveSource struct{}
+
+var recursiveSourceResults = map[string][]string{
+	"example.com":      {"a.corp.example.com", "www.example.com"},
+	"corp.example.com": {"b.corp.example.com", "a.corp.example.com"},
+}
+
+func (s *recursiveSource) Run(_ context.Context, domain string, _ *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result, len(recursiveSourceResults[domain]))
+	for _, subdomain := range recursiveSourceResults[domain] {
+		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
+	}
+	close(results)
+	return results
+}
+
+func (s *recursiveSource) Name() string {
+	return "recursivesource"
+}
+
+func (s *recursiveSource) IsDefault() bool {
+	return false
+}
+
+func (s *recursiveSource) HasRecursiveSupport() bool {
+	return true
+}
+
+func (s *recursiveSource) NeedsKey() bool {
+	return false
+}
+
+func (s *recursiveSource) AddApiKeys(_ []string) {}
+
+// blockingSource runs until its context is done, recording the most runs in progress at once
+type blockingSource struct {
+	running    atomic.Int32
+	maxRunning atomic.Int32
+}
+
+func (s *blockingSource) Run(ctx context.Context, _ string, _ *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	go func() {
+		defer close(results)
+		running := s.running.Add(1)
+		for maxRunning := s.maxRunning.Load(); running > maxRunning && !s.maxRunning.CompareAndSwap(maxRunning, running); {
+			maxRunning = s.maxRunning.Load()
+		}
+		<-ctx.Done()
+		s.running.Add(-1)
+	}()
+	return results
+}
+
+func (s *blockingSource) Name() string {
+	return "blockingsource"
+}
+
+func (s *blockingSource) IsDefault() bool {
+	return false
+}
+
+func (s *blockingSource) HasRecursiveSupport() bool {
+	return true
+}
+
+func (s *blockingSource) NeedsKey() bool {
+	return false
+}
+
+func (s *blockingSource) AddApiKeys(_ []string) {}
+
+func TestRecursiveTargets(t *testing.T) {
+	enumerated := map[string]struct{}{"example.com": {}}
+	hosts := []string{"www.example.com", "a.b.corp.example.com", "c.corp.example.com"}
+
+	targets := recursiveTargets("example.com", hosts, enumerated, 10)
+	assert.Equal(t, []string{"corp.example.com", "b.corp.example.com", "www.example.com", "a.b.corp.example.com", "c.corp.example.com"}, targets)
+	assert.Empty(t, recursiveTargets("example.com", hosts, enumerated, 10), "targets enumerated twice")
+
+	targets = recursiveTargets("example.com", hosts, map[string]struct{}{}, 2)
+	assert.Equal(t, []string{"corp.example.com", "b.corp.example.com"}, targets)
+}
+
+func TestRecursiveEnumeration(t *testing.T) {
+	require.Nil(t, passive.RegisterSource(func() subscraping.Source { return &recursiveSource{} }))
+	t.Cleanup(func() { passive.UnregisterSource("recursivesource") })
+
+	output := &bytes.Buffer{}
+	options := &Options{
+		Domain:             []string{"example.com"},
+		Sources:            []string{"recursivesource"},
+		Threads:            10,
+		Timeout:            10,
+		MaxEnumerationTime: 1,
+		RecursiveDepth:     2,
+		RecursiveBreadth:   10,
+		JSON:               true,
+		Output:             output,
+		ProviderApiKeys:    map[string][]string{},
+	}
+	runner, err := NewRunner(options)
+	require.Nil(t, err)
+	require.Nil(t, runner.RunEnumeration())
+
+	parents := make(map[string]string)
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		var result jsonSourceResult
+		require.Nil(t, jsoniter.Unmarshal(scanner.Bytes(), &result))
+		parents[result.Host] = result.Parent
+	}
+	assert.Equal(t, map[string]string{
+		"a.corp.example.com": "",
+		"www.example.com":    "",
+		"b.corp.example.com": "corp.example.com",
+	}, parents)
+}
+
+func TestEnumerateTargets(t *testing.T) {
+	source := &blockingSource{}
+	registerSource(t, source)
+	runner, err := NewRunner(&Options{
+		Sources:            []string{"blockingsource"},
+		Timeout:            10,
+		MaxEnumerationTime: 10,
+		ProviderApiKeys:    map[string][]string{},
+	})
+	require.Nil(t, err)
+
+	var targets []string
+	for i := 0; i < 2*recursiveConcurrency; i++ {
+		targets = append(targets, fmt.Sprintf("sub%d.example.com", i))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The targets run within the time left of the context rather than the maximum enumeration time
+	start := time.Now()
+	for range runner.enumerateTargets(ctx, targets, []passive.EnumerateOption{passive.WithCustomRateLimit(runner.rateLimit)}) {
+	}
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int32(recursiveConcurrency), source.maxRunning.Load())
+}
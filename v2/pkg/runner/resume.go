@@ -0,0 +1,249 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	fileutil "github.com/projectdiscovery/utils/file"
+)
+
+// resumeCheckpoint keeps track of the progress of an enumeration so that an
+// interrupted run can be resumed without enumerating finished work again.
+// All its methods are no-op on a nil checkpoint.
+type resumeCheckpoint struct {
+	file  string
+	mutex sync.Mutex
+
+	Domains map[string]*domainCheckpoint `json:"domains"`
+}
+
+// domainCheckpoint is the progress of the enumeration of a single domain
+type domainCheckpoint struct {
+	Completed bool `json:"completed,omitempty"`
+	// Sources contains the sources which sent all their results
+	Sources []string `json:"sources,omitempty"`
+	// Hosts contains the hosts found so far along with their sources
+	Hosts map[string][]string `json:"hosts,omitempty"`
+
+	// pendingSources contains the completed sources whose results may not be processed yet
+	pendingSources []string
+}
+
+// loadResumeCheckpoint loads the checkpoint file, starting from scratch if it does not exist
+func loadResumeCheckpoint(file string) (*resumeCheckpoint, error) {
+	checkpoint := &resumeCheckpoint{file: file, Domains: make(map[string]*domainCheckpoint)}
+	if !fileutil.FileExists(file) {
+		return checkpoint, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := jsoniter.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+	if checkpoint.Domains == nil {
+		checkpoint.Domains = make(map[string]*domainCheckpoint)
+	}
+	return checkpoint, nil
+}
+
+// isCompleted returns true if the domain was fully enumerated
+func (c *resumeCheckpoint) isCompleted(domain string) bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	progress, ok := c.Domains[domain]
+	return ok && progress.Completed
+}
+
+// isInProgress returns true if the enumeration of the domain started but did not complete
+func (c *resumeCheckpoint) isInProgress(domain string) bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	progress, ok := c.Domains[domain]
+	return ok && !progress.Completed
+}
+
+// start records the start of the enumeration of the domain and
+// returns the sources and hosts already found by a previous run
+func (c *resumeCheckpoint) start(domain string) ([]string, map[string][]string, error) {
+	if c == nil {
+		return nil, nil, nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	progress, ok := c.Domains[domain]
+	if ok {
+		return progress.Sources, progress.Hosts, nil
+	}
+	c.Domains[domain] = &domainCheckpoint{}
+	return nil, nil, c.save()
+}
+
+// sourceCompleted records that the source sent all its results for the domain.
+// The source is only saved as completed by the next flush, once its results
+// have been processed.
+func (c *resumeCheckpoint) sourceCompleted(domain, source string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if progress, ok := c.Domains[domain]; ok {
+		progress.pendingSources = append(progress.pendingSources, source)
+	}
+}
+
+// flush saves the completed sources of the domain along with the hosts found
+// so far. It must be called by the goroutine processing the results, between
+// two results, so that every result of the completed sources is in sourceMap.
+func (c *resumeCheckpoint) flush(domain string, sourceMap map[string]map[string]struct{}) error {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	progress, ok := c.Domains[domain]
+	if !ok || len(progress.pendingSources) == 0 {
+		return nil
+	}
+	progress.Sources = append(progress.Sources, progress.pendingSources...)
+	progress.pendingSources = nil
+
+	progress.Hosts = make(map[string][]string, len(sourceMap))
+	for host, sources := range sourceMap {
+		for source := range sources {
+			progress.Hosts[host] = append(progress.Hosts[host], source)
+		}
+	}
+	return c.save()
+}
+
+// complete records that the domain was fully enumerated and its results written
+func (c *resumeCheckpoint) complete(domain string) error {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.Domains[domain] = &domainCheckpoint{Completed: true}
+	return c.save()
+}
+
+// remove deletes the checkpoint file once every domain has been enumerated
+func (c *resumeCheckpoint) remove() error {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := os.Remove(c.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// save atomically writes the checkpoint to its file
+func (c *resumeCheckpoint) save() error {
+	data, err := jsoniter.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := c.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, c.file)
+}
+
+// dedupWriter drops the output lines of hosts already written
+// to the output file by an interrupted run.
+type dedupWriter struct {
+	writer  io.Writer
+	written map[string]struct{}
+	pending []byte
+}
+
+// newDedupWriter creates a writer skipping the hosts already present in the file
+func newDedupWriter(writer io.Writer, filename string) (*dedupWriter, error) {
+	dedup := &dedupWriter{writer: writer, written: make(map[string]struct{})}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return dedup, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if host := hostFromLine(scanner.Bytes()); host != "" {
+			dedup.written[host] = struct{}{}
+		}
+	}
+	return dedup, scanner.Err()
+}
+
+// Write buffers the data until complete lines are available and only
+// writes the lines of hosts which were not written before
+func (w *dedupWriter) Write(data []byte) (int, error) {
+	w.pending = append(w.pending, data...)
+	for {
+		index := bytes.IndexByte(w.pending, '\n')
+		if index < 0 {
+			break
+		}
+		line := w.pending[:index+1]
+		w.pending = w.pending[index+1:]
+
+		if host := hostFromLine(line); host != "" {
+			if _, ok := w.written[host]; ok {
+				continue
+			}
+			w.written[host] = struct{}{}
+		}
+		if _, err := w.writer.Write(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// hostFromLine returns the host of an output line in any of the output formats
+func hostFromLine(line []byte) string {
+	line = bytes.TrimSpace(line)
+	if bytes.HasPrefix(line, []byte("{")) {
+		var result struct {
+			Host string `json:"host"`
+		}
+		if err := jsoniter.Unmarshal(line, &result); err != nil {
+			return ""
+		}
+		return result.Host
+	}
+	host, _, _ := strings.Cut(string(line), ",")
+	return host
+}
@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeCheckpoint(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "resume.json")
+	checkpoint, err := loadResumeCheckpoint(file)
+	require.Nil(t, err)
+
+	sources, hosts, err := checkpoint.start("example.com")
+	require.Nil(t, err)
+	require.Empty(t, sources)
+	require.Empty(t, hosts)
+
+	checkpoint.sourceCompleted("example.com", "crtsh")
+	require.Nil(t, checkpoint.flush("example.com", map[string]map[string]struct{}{
+		"www.example.com": {"crtsh": {}},
+	}))
+	_, _, err = checkpoint.start("hackerone.com")
+	require.Nil(t, err)
+	require.Nil(t, checkpoint.complete("hackerone.com"))
+
+	// Simulate an interrupted run loading the checkpoint again
+	checkpoint, err = loadResumeCheckpoint(file)
+	require.Nil(t, err)
+	require.True(t, checkpoint.isCompleted("hackerone.com"))
+	require.True(t, checkpoint.isInProgress("example.com"))
+
+	sources, hosts, err = checkpoint.start("example.com")
+	require.Nil(t, err)
+	require.Equal(t, []string{"crtsh"}, sources)
+	require.Equal(t, map[string][]string{"www.example.com": {"crtsh"}}, hosts)
+
+	require.Nil(t, checkpoint.remove())
+	require.NoFileExists(t, file)
+}
+
+func TestDedupWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "output.txt")
+	require.Nil(t, os.WriteFile(file, []byte("www.example.com\n{\"host\":\"api.example.com\",\"input\":\"example.com\"}\n"), 0644))
+
+	output := &bytes.Buffer{}
+	writer, err := newDedupWriter(output, file)
+	require.Nil(t, err)
+
+	_, err = writer.Write([]byte("www.example.com\nmail.exa"))
+	require.Nil(t, err)
+	_, err = writer.Write([]byte("mple.com,[crtsh]\n{\"host\":\"api.example.com\"}\nmail.example.com\n"))
+	require.Nil(t, err)
+	require.Equal(t, "mail.example.com,[crtsh]\n", output.String())
+}
@@ -3,6 +3,7 @@ package runner
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"math"
 	"os"
@@ -10,15 +11,21 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/projectdiscovery/gologger"
 	contextutil "github.com/projectdiscovery/utils/context"
 	fileutil "github.com/projectdiscovery/utils/file"
 	mapsutil "github.com/projectdiscovery/utils/maps"
 
+	"github.com/YouChenJun/subfinder-plus/pkg/asn"
+	"github.com/YouChenJun/subfinder-plus/pkg/database"
 	"github.com/YouChenJun/subfinder-plus/pkg/passive"
+	"github.com/YouChenJun/subfinder-plus/pkg/permutation"
 	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+	"github.com/YouChenJun/subfinder-plus/pkg/takeover"
 )
 
 // Runner is an instance of the subdomain enumeration
@@ -28,6 +35,25 @@ type Runner struct {
 	passiveAgent   *passive.Agent
 	resolverClient *resolve.Resolver
 	rateLimit      *subscraping.CustomRateLimit
+	resultDB       *database.DB
+	resume         *resumeCheckpoint
+	// wordlist contains the words resolved under every domain in brute-force mode
+	wordlist []string
+	// permutationWords contains the words used to alter the subdomains found
+	permutationWords []string
+	// asnDB maps the resolved IP addresses to their autonomous systems
+	asnDB *asn.Database
+	// ptrQueries counts the PTR queries sent by the netblock sweeps of every domain
+	ptrQueries atomic.Int64
+	// asnSummary groups the resolved hosts of every enumerated domain by autonomous system
+	asnSummary *asn.Summary
+	// takeoverFingerprints contains the services the CNAME targets of the resolved hosts are checked against
+	takeoverFingerprints []takeover.Fingerprint
+	// statistics holds the source statistics of every enumerated domain
+	statistics      map[string]map[string]subscraping.Statistics
+	statisticsMutex sync.Mutex
+	// outputMutex serializes writes of domains enumerated concurrently
+	outputMutex sync.Mutex
 }
 
 // NewRunner creates a new runner struct instance by parsing
@@ -35,21 +61,24 @@ type Runner struct {
 // and setting up loggers, etc.
 func NewRunner(options *Options) (*Runner, error) {
 	options.ConfigureOutput()
-	runner := &Runner{options: options}
+	runner := &Runner{options: options, statistics: make(map[string]map[string]subscraping.Statistics)}
 
-	// Check if the application loading with any provider configuration, then take it
+	// Initialize the passive subdomain enumeration engine
+	runner.initializePassiveEngine()
+
+	// Use the API keys given by the caller if any. Otherwise check if the
+	// application loading with any provider configuration, then take it
 	// Otherwise load the default provider config
-	if fileutil.FileExists(options.ProviderConfig) {
+	if options.ProviderApiKeys != nil {
+		runner.passiveAgent.AddApiKeys(options.ProviderApiKeys)
+	} else if fileutil.FileExists(options.ProviderConfig) {
 		gologger.Info().Msgf("Loading provider config from %s", options.ProviderConfig)
-		options.loadProvidersFrom(options.ProviderConfig)
+		runner.loadProvidersFrom(options.ProviderConfig)
 	} else {
 		gologger.Info().Msgf("Loading provider config from the default location: %s", defaultProviderConfigLocation)
-		options.loadProvidersFrom(defaultProviderConfigLocation)
+		runner.loadProvidersFrom(defaultProviderConfigLocation)
 	}
 
-	// Initialize the passive subdomain enumeration engine
-	runner.initializePassiveEngine()
-
 	// Initialize the subdomain resolver
 	err := runner.initializeResolver()
 	if err != nil {
@@ -58,20 +87,82 @@ func NewRunner(options *Options) (*Runner, error) {
 
 	// Initialize the custom rate limit
 	runner.rateLimit = &subscraping.CustomRateLimit{
-		Custom: mapsutil.SyncLockMap[string, uint]{
-			Map: make(map[string]uint),
+		Custom: mapsutil.SyncLockMap[string, subscraping.RateLimit]{
+			Map: make(map[string]subscraping.RateLimit),
 		},
 	}
 
 	for source, sourceRateLimit := range options.RateLimits.AsMap() {
 		if sourceRateLimit.MaxCount > 0 && sourceRateLimit.MaxCount <= math.MaxUint {
-			_ = runner.rateLimit.Custom.Set(source, sourceRateLimit.MaxCount)
+			_ = runner.rateLimit.Custom.Set(source, subscraping.RateLimit{MaxCount: sourceRateLimit.MaxCount, Duration: sourceRateLimit.Duration})
+		}
+	}
+
+	// Load the checkpoint of an interrupted run
+	if options.Resume != "" {
+		runner.resume, err = loadResumeCheckpoint(options.Resume)
+		if err != nil {
+			return nil, fmt.Errorf("could not load checkpoint %s: %s", options.Resume, err)
+		}
+	}
+
+	// Load the words to brute-force
+	if options.Wordlist != "" {
+		runner.wordlist, err = loadFromFile(options.Wordlist)
+		if err != nil {
+			return nil, fmt.Errorf("could not read wordlist %s: %s", options.Wordlist, err)
+		}
+	}
+
+	// Load the words to alter the subdomains found with
+	runner.permutationWords = permutation.DefaultWords
+	if options.PermutationWords != "" {
+		runner.permutationWords, err = loadFromFile(options.PermutationWords)
+		if err != nil {
+			return nil, fmt.Errorf("could not read permutation words %s: %s", options.PermutationWords, err)
+		}
+	}
+
+	// Load the IP-to-ASN database
+	if options.ASNDatabase != "" {
+		runner.asnDB, err = asn.Load(options.ASNDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("could not load ASN database %s: %s", options.ASNDatabase, err)
+		}
+		runner.asnSummary = asn.NewSummary()
+		gologger.Info().Msgf("Loaded %d ranges from ASN database %s", runner.asnDB.Len(), options.ASNDatabase)
+	}
+
+	// Load the fingerprints of the services dangling CNAMEs can be claimed on
+	if options.Takeover {
+		runner.takeoverFingerprints, err = takeover.Load(options.TakeoverFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load takeover fingerprints: %s", err)
+		}
+	}
+
+	// Open the database of results found by previous runs
+	if options.ResultDatabase != "" {
+		runner.resultDB, err = database.Open(options.ResultDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("could not open database %s: %s", options.ResultDatabase, err)
 		}
 	}
 
 	return runner, nil
 }
 
+// Close releases the resources held by the runner
+func (r *Runner) Close() error {
+	if r.resolverClient != nil {
+		_ = r.resolverClient.Close()
+	}
+	if r.resultDB != nil {
+		return r.resultDB.Close()
+	}
+	return nil
+}
+
 // RunEnumeration wraps RunEnumerationWithCtx with an empty context
 func (r *Runner) RunEnumeration() error {
 	ctx, _ := contextutil.WithValues(context.Background(), contextutil.ContextArg("All"), contextutil.ContextArg(strconv.FormatBool(r.options.All)))
@@ -112,58 +203,136 @@ func (r *Runner) EnumerateMultipleDomains(reader io.Reader, writers []io.Writer)
 }
 
 // EnumerateMultipleDomainsWithCtx enumerates subdomains for multiple domains
-// We keep enumerating subdomains for a given domain until we reach an error
+// using a pool of DomainConcurrency workers. All the domains share the same
+// rate limiter. We stop queueing new domains once a domain returns an error.
 func (r *Runner) EnumerateMultipleDomainsWithCtx(ctx context.Context, reader io.Reader, writers []io.Writer) error {
-	var err error
+	multiRateLimiter, err := r.passiveAgent.NewMultiRateLimiter(ctx, r.options.RateLimit, r.rateLimit)
+	if err != nil {
+		return fmt.Errorf("could not init multi rate limiter: %s", err)
+	}
+	defer multiRateLimiter.Stop()
+
+	// Keep pruning the resolvers which become unhealthy during the enumeration
+	if r.resolvesSubdomains() && !r.options.NoResolverCheck && r.options.ResolverInterval > 0 {
+		healthCheckCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		r.resolverClient.StartHealthChecks(healthCheckCtx, r.options.ResolverInterval, resolverCheckTimeout)
+	}
+
+	var (
+		firstErr   error
+		errorMutex sync.Mutex
+	)
+	failed := func() bool {
+		errorMutex.Lock()
+		defer errorMutex.Unlock()
+		return firstErr != nil
+	}
+
+	domains := make(chan string)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < max(r.options.DomainConcurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for domain := range domains {
+				err := r.enumerateDomainToOutputs(ctx, domain, writers, passive.WithMultiRateLimiter(multiRateLimiter))
+				if err != nil {
+					errorMutex.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errorMutex.Unlock()
+				}
+			}
+		}()
+	}
+
 	scanner := bufio.NewScanner(reader)
 	ip, _ := regexp.Compile(`^([0-9\.]+$)`)
-	for scanner.Scan() {
+	for scanner.Scan() && !failed() {
 		domain := preprocessDomain(scanner.Text())
 		domain = replacer.Replace(domain)
 
 		if domain == "" || (r.options.ExcludeIps && ip.MatchString(domain)) {
 			continue
 		}
+		if r.resume.isCompleted(domain) {
+			gologger.Info().Msgf("Skipping %s as it was enumerated by a previous run\n", domain)
+			continue
+		}
+		domains <- domain
+	}
+	close(domains)
+	wg.Wait()
 
-		var file *os.File
-		// If the user has specified an output file, use that output file instead
-		// of creating a new output file for each domain. Else create a new file
-		// for each domain in the directory.
-		if r.options.OutputFile != "" {
-			outputWriter := NewOutputWriter(r.options.JSON)
-			file, err = outputWriter.createFile(r.options.OutputFile, true)
-			if err != nil {
-				gologger.Error().Msgf("Could not create file %s for %s: %s\n", r.options.OutputFile, r.options.Domain, err)
-				return err
-			}
-
-			_, err = r.EnumerateSingleDomainWithCtx(ctx, domain, append(writers, file))
-
-			file.Close()
-		} else if r.options.OutputDirectory != "" {
-			outputFile := path.Join(r.options.OutputDirectory, domain)
-			if r.options.JSON {
-				outputFile += ".json"
-			} else {
-				outputFile += ".txt"
-			}
+	if r.options.Statistics {
+		printResolverStatistics(r.resolverClient.Statistics())
+	}
+	if !r.options.Silent {
+		printASNSummary(r.asnSummary.Entries())
+	}
 
-			outputWriter := NewOutputWriter(r.options.JSON)
-			file, err = outputWriter.createFile(outputFile, false)
-			if err != nil {
-				gologger.Error().Msgf("Could not create file %s for %s: %s\n", r.options.OutputFile, r.options.Domain, err)
-				return err
-			}
+	// Every domain was enumerated, the checkpoint is not needed anymore
+	if firstErr == nil && ctx.Err() == nil {
+		if err := r.resume.remove(); err != nil {
+			gologger.Warning().Msgf("Could not remove checkpoint %s: %s\n", r.options.Resume, err)
+		}
+	}
+	return firstErr
+}
 
-			_, err = r.EnumerateSingleDomainWithCtx(ctx, domain, append(writers, file))
+// enumerateDomainToOutputs enumerates a single domain, adding the output
+// file of the domain to the writers if one was requested
+func (r *Runner) enumerateDomainToOutputs(ctx context.Context, domain string, writers []io.Writer, options ...passive.EnumerateOption) error {
+	// The output of a domain interrupted by a previous run is appended to,
+	// skipping the hosts which were already written
+	resuming := r.resume.isInProgress(domain)
 
-			file.Close()
+	var err error
+	var outputFile string
+	var file *os.File
+	// If the user has specified an output file, use that output file instead
+	// of creating a new output file for each domain. Else create a new file
+	// for each domain in the directory.
+	if r.options.OutputFile != "" {
+		outputFile = r.options.OutputFile
+		outputWriter := NewOutputWriter(r.options.JSON)
+		file, err = outputWriter.createFile(outputFile, true)
+		if err != nil {
+			gologger.Error().Msgf("Could not create file %s for %s: %s\n", outputFile, domain, err)
+			return err
+		}
+	} else if r.options.OutputDirectory != "" {
+		outputFile = path.Join(r.options.OutputDirectory, domain)
+		if r.options.JSON {
+			outputFile += ".json"
 		} else {
-			_, err = r.EnumerateSingleDomainWithCtx(ctx, domain, writers)
+			outputFile += ".txt"
 		}
+
+		outputWriter := NewOutputWriter(r.options.JSON)
+		file, err = outputWriter.createFile(outputFile, resuming)
 		if err != nil {
+			gologger.Error().Msgf("Could not create file %s for %s: %s\n", outputFile, domain, err)
 			return err
 		}
 	}
-	return nil
+
+	if file != nil {
+		defer file.Close()
+
+		var fileWriter io.Writer = file
+		if resuming {
+			fileWriter, err = newDedupWriter(file, outputFile)
+			if err != nil {
+				gologger.Error().Msgf("Could not read file %s for %s: %s\n", outputFile, domain, err)
+				return err
+			}
+		}
+		// Copy the writers as they are shared by the concurrent domains
+		writers = append(append(make([]io.Writer, 0, len(writers)+1), writers...), fileWriter)
+	}
+	_, err = r.enumerateSingleDomain(ctx, domain, writers, options...)
+	return err
 }
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/YouChenJun/subfinder-plus/pkg/asn"
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 	"github.com/projectdiscovery/gologger"
 	"golang.org/x/exp/maps"
@@ -24,12 +26,12 @@ func printStatistics(stats map[string]subscraping.Statistics) {
 		if sourceStats.Skipped {
 			skipped = append(skipped, fmt.Sprintf(" %s", source))
 		} else {
-			lines = append(lines, fmt.Sprintf(" %-20s %-10s %10d %10d", source, sourceStats.TimeTaken.Round(time.Millisecond).String(), sourceStats.Results, sourceStats.Errors))
+			lines = append(lines, fmt.Sprintf(" %-20s %-10s %10d %10d %12d %10d %10d %10d", source, sourceStats.TimeTaken.Round(time.Millisecond).String(), sourceStats.Unique(), sourceStats.Duplicates, sourceStats.OutOfScope, sourceStats.Filtered, sourceStats.Errors, sourceStats.Retries))
 		}
 	}
 
 	if len(lines) > 0 {
-		gologger.Print().Msgf("\n Source               Duration      Results     Errors\n%s\n", strings.Repeat("─", 56))
+		gologger.Print().Msgf("\n Source               Duration      Results Duplicates Out-of-scope   Filtered     Errors    Retries\n%s\n", strings.Repeat("─", 101))
 		gologger.Print().Msg(strings.Join(lines, "\n"))
 		gologger.Print().Msgf("\n")
 	}
@@ -41,6 +43,75 @@ func printStatistics(stats map[string]subscraping.Statistics) {
 	}
 }
 
+func printResolverStatistics(stats map[string]resolve.ResolverStatistics) {
+	if len(stats) == 0 {
+		return
+	}
+
+	resolvers := maps.Keys(stats)
+	sort.Strings(resolvers)
+
+	var lines []string
+	for _, resolver := range resolvers {
+		resolverStats := stats[resolver]
+		status := "healthy"
+		if !resolverStats.Healthy {
+			status = "pruned: " + resolverStats.Reason
+		}
+		lines = append(lines, fmt.Sprintf(" %-24s %-10s %8d %8d  %s", resolver, resolverStats.Latency.Round(time.Millisecond).String(), resolverStats.Checks, resolverStats.Errors, status))
+	}
+
+	gologger.Print().Msgf("\n Resolver                 Latency      Checks   Errors  Status\n%s\n", strings.Repeat("─", 80))
+	gologger.Print().Msg(strings.Join(lines, "\n"))
+	gologger.Print().Msgf("\n")
+}
+
+func printASNSummary(entries []asn.SummaryEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf(" AS%-10d %8d %8d  %-7s  %s", entry.Number, entry.Hosts, entry.IPs, entry.Country, entry.Name))
+	}
+
+	gologger.Print().Msgf("\n ASN            Hosts      IPs  Country  Name\n%s\n", strings.Repeat("─", 80))
+	gologger.Print().Msg(strings.Join(lines, "\n"))
+	gologger.Print().Msgf("\n")
+}
+
+// GetStatistics returns the source statistics summed over all the enumerated domains
 func (r *Runner) GetStatistics() map[string]subscraping.Statistics {
-	return r.passiveAgent.GetStatistics()
+	r.statisticsMutex.Lock()
+	defer r.statisticsMutex.Unlock()
+
+	stats := make(map[string]subscraping.Statistics)
+	for _, domainStats := range r.statistics {
+		for source, sourceStats := range domainStats {
+			total, ok := stats[source]
+			if !ok {
+				total.Skipped = true
+			}
+			total.TimeTaken += sourceStats.TimeTaken
+			total.Errors += sourceStats.Errors
+			total.Results += sourceStats.Results
+			total.Duplicates += sourceStats.Duplicates
+			total.OutOfScope += sourceStats.OutOfScope
+			total.Filtered += sourceStats.Filtered
+			total.Retries += sourceStats.Retries
+			// A source is only skipped if it was skipped for every domain
+			total.Skipped = total.Skipped && sourceStats.Skipped
+			stats[source] = total
+		}
+	}
+	return stats
+}
+
+// GetDomainStatistics returns the source statistics of a single enumerated domain
+func (r *Runner) GetDomainStatistics(domain string) map[string]subscraping.Statistics {
+	r.statisticsMutex.Lock()
+	defer r.statisticsMutex.Unlock()
+
+	return r.statistics[domain]
 }
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+	"github.com/YouChenJun/subfinder-plus/pkg/takeover"
+)
+
+// checkTakeovers checks the CNAME targets of the resolved hosts against the
+// takeover fingerprints and records the service of the candidates found.
+// The dangling hosts, whose CNAME chain does not lead to an address, are
+// only added to the results when they are candidates.
+func (r *Runner) checkTakeovers(ctx context.Context, domain string, foundResults, danglingResults map[string]resolve.Result) {
+	checker := &takeover.Checker{Fingerprints: r.takeoverFingerprints, NXDomain: r.isNXDomain}
+	if r.options.TakeoverHTTP {
+		session, err := subscraping.NewSession(domain, r.options.Proxy, nil, r.options.Timeout, "")
+		if err != nil {
+			gologger.Warning().Msgf("Could not create session to fetch the hosts of %s: %s\n", domain, err)
+		} else {
+			defer session.Close()
+			checker.Client = session.Client
+		}
+	}
+
+	hosts := make(chan resolve.Result)
+	go func() {
+		defer close(hosts)
+		for _, results := range []map[string]resolve.Result{foundResults, danglingResults} {
+			for _, result := range results {
+				if len(result.Records.CNAME) == 0 || result.Wildcard != "" {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case hosts <- result:
+				}
+			}
+		}
+	}()
+
+	var mutex sync.Mutex
+	services := make(map[string]string)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < r.options.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for result := range hosts {
+				service, ok := checker.Check(ctx, result.Host, result.Records.CNAME)
+				if !ok {
+					continue
+				}
+				gologger.Info().Msgf("Possible takeover of %s on %s\n", result.Host, service)
+				mutex.Lock()
+				services[result.Host] = service
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for host, service := range services {
+		result, ok := foundResults[host]
+		if !ok {
+			result = danglingResults[host]
+		}
+		result.Takeover = service
+		foundResults[host] = result
+	}
+}
+
+// isNXDomain returns true if the name does not exist according to the resolvers
+func (r *Runner) isNXDomain(name string) (bool, error) {
+	data, err := r.resolverClient.QueryTypes(name, dns.TypeA)
+	if err != nil {
+		return false, err
+	}
+	return data.StatusCodeRaw == dns.RcodeNameError, nil
+}
@@ -0,0 +1,138 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// tlsprobeSource is the source of the subdomains found in the certificates served by the resolved hosts
+const tlsprobeSource = "tlsprobe"
+
+// tlsProbeTargets returns the addresses of the resolved hosts on every port,
+// leaving out the wildcard subdomains
+func tlsProbeTargets(results map[string]resolve.Result, ports []string) []string {
+	hosts := make([]string, 0, len(results))
+	for host, result := range results {
+		if result.Wildcard != "" {
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	targets := make([]string, 0, len(hosts)*len(ports))
+	for _, host := range hosts {
+		for _, port := range ports {
+			targets = append(targets, net.JoinHostPort(host, port))
+		}
+	}
+	return targets
+}
+
+// probeTLS connects to every target and returns the names in scope of the
+// domain found in the certificate chain it presents as results. The
+// connections go through the proxy of the run.
+func (r *Runner) probeTLS(ctx context.Context, domain string, targets []string, statistics *subscraping.StatisticsCollector) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	if len(targets) == 0 {
+		close(results)
+		return results
+	}
+	session, err := subscraping.NewSession(domain, r.options.Proxy, nil, r.options.Timeout, "")
+	if err != nil {
+		gologger.Warning().Msgf("Could not create session to probe %s: %s\n", domain, err)
+		close(results)
+		return results
+	}
+
+	gologger.Info().Msgf("Probing %d addresses of %s for TLS certificates\n", len(targets), domain)
+	startTime := time.Now()
+	statistics.AddSource(tlsprobeSource)
+
+	addresses := make(chan string)
+	go func() {
+		defer close(addresses)
+		for _, target := range targets {
+			select {
+			case <-ctx.Done():
+				return
+			case addresses <- target:
+			}
+		}
+	}()
+
+	var mutex sync.Mutex
+	seen := make(map[string]struct{})
+	wg := &sync.WaitGroup{}
+	for i := 0; i < r.options.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for address := range addresses {
+				names, err := certificateNames(ctx, session, address)
+				if err != nil {
+					gologger.Debug().Msgf("Could not probe %s for TLS certificates: %s\n", address, err)
+					continue
+				}
+				for _, name := range session.Extractor.Extract(strings.Join(names, "\n")) {
+					name = replacer.Replace(name)
+					mutex.Lock()
+					_, ok := seen[name]
+					seen[name] = struct{}{}
+					mutex.Unlock()
+					if ok {
+						continue
+					}
+					statistics.AddResult(tlsprobeSource)
+					results <- subscraping.Result{Type: subscraping.Subdomain, Source: tlsprobeSource, Value: name}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		session.Close()
+		statistics.AddTimeTaken(tlsprobeSource, time.Since(startTime))
+		close(results)
+	}()
+	return results
+}
+
+// certificateNames completes a TLS handshake with the address and returns the
+// common names and the subject alternative names of the peer certificate chain
+func certificateNames(ctx context.Context, session *subscraping.Session, address string) ([]string, error) {
+	if timeout := session.Client.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := session.Dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(address)
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	defer tlsConn.Close()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, certificate := range tlsConn.ConnectionState().PeerCertificates {
+		names = append(names, certificate.Subject.CommonName)
+		names = append(names, certificate.DNSNames...)
+	}
+	return names, nil
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+func TestTLSProbeTargets(t *testing.T) {
+	results := map[string]resolve.Result{
+		"b.example.com": {},
+		"a.example.com": {},
+		"c.example.com": {Wildcard: "example.com"},
+	}
+
+	assert.Equal(t, []string{"a.example.com:443", "a.example.com:8443", "b.example.com:443", "b.example.com:8443"}, tlsProbeTargets(results, []string{"443", "8443"}))
+}
+
+func TestProbeTLS(t *testing.T) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{testCertificate(t, "www.example.com", "*.dev.example.com", "api.example.com", "example.org")}})
+	require.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	// The connections are tunneled through an HTTP proxy
+	var tunnels atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodConnect {
+			http.Error(w, "", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			http.Error(w, "", http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tunnels.Add(1)
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() { _, _ = io.Copy(upstream, conn) }()
+		_, _ = io.Copy(conn, upstream)
+	}))
+	defer proxy.Close()
+
+	runner := &Runner{options: &Options{Threads: 2, Timeout: 5, Proxy: proxy.URL}}
+	statistics := subscraping.NewStatisticsCollector()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	targets := []string{net.JoinHostPort("a.example.com", port), net.JoinHostPort("b.example.com", port)}
+
+	var names []string
+	for result := range runner.probeTLS(context.Background(), "example.com", targets, statistics) {
+		assert.Equal(t, tlsprobeSource, result.Source)
+		names = append(names, result.Value)
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, []string{"api.example.com", "dev.example.com", "www.example.com"}, names)
+	assert.Equal(t, int32(2), tunnels.Load())
+	assert.Equal(t, 3, statistics.Statistics()[tlsprobeSource].Results)
+}
+
+// testCertificate creates a self-signed certificate with the common name and the alternative names
+func testCertificate(t *testing.T, commonName string, dnsNames ...string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	return tls.Certificate{Certificate: [][]byte{certificate}, PrivateKey: key}
+}
@@ -3,17 +3,28 @@ package runner
 import (
 	"errors"
 	"fmt"
+	"net"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/passive"
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/gologger/formatter"
 	"github.com/projectdiscovery/gologger/levels"
-	mapsutil "github.com/projectdiscovery/utils/maps"
 	sliceutil "github.com/projectdiscovery/utils/slice"
 )
 
+// Validate normalizes the domains of the options and validates them, building the
+// settings derived from the options. The options parsed from the command line go
+// through it as well as the options of the jobs submitted to the API server.
+func (options *Options) Validate() error {
+	options.preProcessDomains()
+	return options.validateOptions()
+}
+
 // validateOptions validates the configuration options passed
 func (options *Options) validateOptions() error {
 	// Check if domain, list of domains, or stdin info was provided.
@@ -35,11 +46,102 @@ func (options *Options) validateOptions() error {
 		return errors.New("timeout cannot be zero")
 	}
 
+	if options.RecursiveDepth < 0 {
+		return errors.New("recursive depth cannot be negative")
+	}
+	if options.RecursiveDepth > 0 && options.RecursiveBreadth <= 0 {
+		return errors.New("recursive breadth must be greater than zero")
+	}
+
+	if options.Permute && options.PermutationLimit <= 0 {
+		return errors.New("permutation limit must be greater than zero")
+	}
+
+	if options.NSEC3Output != "" && !options.ZoneEnum {
+		return errors.New("nsec3-output flag must be used with zone-enum option")
+	}
+
 	// Always remove wildcard with hostip
 	if options.HostIP && !options.RemoveWildcard {
 		return errors.New("hostip flag must be used with RemoveWildcard option")
 	}
 
+	if options.PTRSweep {
+		if !options.RemoveWildcard {
+			return errors.New("ptr-sweep flag must be used with RemoveWildcard option")
+		}
+		if options.PTRNetblock < 16 || options.PTRNetblock > 32 {
+			return errors.New("ptr netblock must be between 16 and 32")
+		}
+		if options.PTRLimit <= 0 {
+			return errors.New("ptr limit must be greater than zero")
+		}
+	}
+
+	if options.TLSProbe {
+		if !options.RemoveWildcard {
+			return errors.New("tls-probe flag must be used with RemoveWildcard option")
+		}
+		for _, port := range options.TLSPorts {
+			if number, err := strconv.Atoi(port); err != nil || number <= 0 || number > 65535 {
+				return fmt.Errorf("invalid tls probe port %s", port)
+			}
+		}
+	}
+
+	if options.Probe {
+		if !options.RemoveWildcard {
+			return errors.New("probe flag must be used with RemoveWildcard option")
+		}
+		if options.ProbeThreads <= 0 {
+			return errors.New("probe threads must be greater than zero")
+		}
+		if options.ProbeTimeout <= 0 {
+			return errors.New("probe timeout must be greater than zero")
+		}
+	}
+
+	if options.Takeover && !options.RemoveWildcard {
+		return errors.New("takeover flag must be used with RemoveWildcard option")
+	}
+	if (options.TakeoverFile != "" || options.TakeoverHTTP) && !options.Takeover {
+		return errors.New("takeover-fingerprints and takeover-http flags must be used with takeover option")
+	}
+
+	if options.ASNDatabase != "" && !options.RemoveWildcard {
+		return errors.New("asn-db flag must be used with RemoveWildcard option")
+	}
+
+	if (options.ResolverCheckName == "") != (len(options.ResolverCheckIPs) == 0) {
+		return errors.New("resolver-check-name and resolver-check-ips flags must be used together")
+	}
+	for _, ip := range options.ResolverCheckIPs {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid resolver check ip %s", ip)
+		}
+	}
+
+	if options.LabelWildcard && !options.RemoveWildcard {
+		return errors.New("label-wildcard flag must be used with RemoveWildcard option")
+	}
+
+	if len(options.RecordTypes) > 0 {
+		if !options.RemoveWildcard {
+			return errors.New("record-type flag must be used with RemoveWildcard option")
+		}
+		if _, err := resolve.RecordTypes(options.RecordTypes); err != nil {
+			return err
+		}
+	}
+
+	if options.Metadata && !options.JSON {
+		return errors.New("metadata flag must be used with json option")
+	}
+
+	if options.NewOnly && options.ResultDatabase == "" {
+		return errors.New("new-only flag must be used with db option")
+	}
+
 	if options.Match != nil {
 		options.matchRegexes = make([]*regexp.Regexp, len(options.Match))
 		var err error
@@ -59,14 +161,71 @@ func (options *Options) validateOptions() error {
 		}
 	}
 
-	sources := mapsutil.GetKeys(passive.NameSourceMap)
+	sources := passive.SourceNames()
 	for source := range options.RateLimits.AsMap() {
 		if !sliceutil.Contains(sources, source) {
 			return fmt.Errorf("invalid source %s specified in -rls flag", source)
 		}
 	}
+
+	maxPages, err := parseSourceLimits("mp", options.MaxPages, sources, 1)
+	if err != nil {
+		return err
+	}
+	maxResults, err := parseSourceLimits("mr", options.MaxResults, sources, 1)
+	if err != nil {
+		return err
+	}
+	options.pageLimits = make(map[string]subscraping.PageLimits)
+	for source, pages := range maxPages {
+		limits := options.pageLimits[source]
+		limits.MaxPages = pages
+		options.pageLimits[source] = limits
+	}
+	for source, results := range maxResults {
+		limits := options.pageLimits[source]
+		limits.MaxResults = results
+		options.pageLimits[source] = limits
+	}
+
+	if options.Retries < 0 {
+		return errors.New("retries must not be negative")
+	}
+	sourceRetries, err := parseSourceLimits("srt", options.SourceRetries, sources, 0)
+	if err != nil {
+		return err
+	}
+	options.retryPolicies = make(map[string]subscraping.RetryPolicy)
+	for source, retries := range sourceRetries {
+		policy := options.retryPolicy()
+		policy.MaxRetries = retries
+		options.retryPolicies[source] = policy
+	}
 	return nil
 }
+
+// retryPolicy returns the retry policy of the sources without their own number of retries
+func (options *Options) retryPolicy() subscraping.RetryPolicy {
+	return subscraping.RetryPolicy{MaxRetries: options.Retries, BaseDelay: options.RetryDelay, MaxDelay: subscraping.DefaultRetryPolicy.MaxDelay}
+}
+
+// parseSourceLimits reads the limits of the sources given in key=value format with the flag,
+// which must be at least the given minimum
+func parseSourceLimits(flag string, values, sources []string, minimum int) (map[string]int, error) {
+	limits := make(map[string]int, len(values))
+	for _, value := range values {
+		source, count, ok := strings.Cut(value, "=")
+		limit, err := strconv.Atoi(count)
+		if !ok || err != nil || limit < minimum {
+			return nil, fmt.Errorf("invalid value %s specified in -%s flag", value, flag)
+		}
+		if !sliceutil.Contains(sources, source) {
+			return nil, fmt.Errorf("invalid source %s specified in -%s flag", source, flag)
+		}
+		limits[source] = limit
+	}
+	return limits, nil
+}
 func stripRegexString(val string) string {
 	val = strings.ReplaceAll(val, ".", "\\.")
 	val = strings.ReplaceAll(val, "*", ".*")
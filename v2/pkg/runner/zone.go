@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+	"github.com/YouChenJun/subfinder-plus/pkg/zone"
+)
+
+const (
+	// axfrSource is the source of the subdomains found by zone transfers
+	axfrSource = "axfr"
+	// nsecSource is the source of the subdomains found by walking the NSEC chain of the zone
+	nsecSource = "nsec"
+	// zoneTimeout is the timeout of the queries sent to the authoritative nameservers
+	zoneTimeout = 10 * time.Second
+	// zoneWalkLimit is the maximum number of queries sent to walk a zone
+	zoneWalkLimit = 10000
+)
+
+// enumerateZone requests zone transfers of the domain from its authoritative nameservers
+// and walks the zone if none of them allows it, returning the names found as results
+func (r *Runner) enumerateZone(ctx context.Context, domain string, statistics *subscraping.StatisticsCollector) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+	if !r.options.ZoneEnum {
+		close(results)
+		return results
+	}
+
+	go func() {
+		defer close(results)
+
+		nameservers, err := r.nameservers(domain)
+		if err != nil {
+			gologger.Warning().Msgf("Could not find the nameservers of %s: %s\n", domain, err)
+			return
+		}
+		if len(nameservers) == 0 {
+			gologger.Warning().Msgf("No nameserver found for %s, skipping zone enumeration\n", domain)
+			return
+		}
+
+		startTime := time.Now()
+		statistics.AddSource(axfrSource)
+		transferred := false
+		for _, nameserver := range nameservers {
+			if ctx.Err() != nil {
+				break
+			}
+			names, err := zone.Transfer(ctx, nameserver, domain, zoneTimeout)
+			if err != nil {
+				statistics.AddError(axfrSource)
+				gologger.Verbose().Msgf("Zone transfer of %s from %s failed: %s\n", domain, nameserver, err)
+				continue
+			}
+			transferred = true
+			gologger.Info().Msgf("Zone transfer of %s allowed by %s\n", domain, nameserver)
+			r.sendZoneNames(ctx, results, axfrSource, names, statistics)
+		}
+		statistics.AddTimeTaken(axfrSource, time.Since(startTime))
+		if transferred || ctx.Err() != nil {
+			return
+		}
+
+		startTime = time.Now()
+		statistics.AddSource(nsecSource)
+		defer func() { statistics.AddTimeTaken(nsecSource, time.Since(startTime)) }()
+		for _, nameserver := range nameservers {
+			if ctx.Err() != nil {
+				return
+			}
+			walk, err := zone.Walk(ctx, nameserver, domain, zoneTimeout, zoneWalkLimit)
+			if err != nil {
+				statistics.AddError(nsecSource)
+				gologger.Verbose().Msgf("Zone walk of %s on %s failed: %s\n", domain, nameserver, err)
+			}
+			if walk == nil {
+				continue
+			}
+			r.sendZoneNames(ctx, results, nsecSource, walk.Names, statistics)
+			if len(walk.Hashes) > 0 {
+				r.writeNSEC3Hashes(domain, walk.Hashes)
+			}
+			if err == nil {
+				return
+			}
+		}
+	}()
+	return results
+}
+
+// nameservers returns the addresses of the authoritative nameservers of the domain
+func (r *Runner) nameservers(domain string) ([]string, error) {
+	data, err := r.resolverClient.QueryTypes(domain, dns.TypeNS)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameservers []string
+	for _, ns := range data.NS {
+		addresses, err := r.resolverClient.QueryTypes(ns, dns.TypeA, dns.TypeAAAA)
+		if err != nil {
+			continue
+		}
+		for _, ip := range append(addresses.A, addresses.AAAA...) {
+			nameservers = append(nameservers, net.JoinHostPort(ip, "53"))
+		}
+	}
+	return nameservers, nil
+}
+
+// sendZoneNames sends the names found in the zone as results of the source
+func (r *Runner) sendZoneNames(ctx context.Context, results chan<- subscraping.Result, source string, names []string, statistics *subscraping.StatisticsCollector) {
+	for _, name := range names {
+		statistics.AddResult(source)
+		select {
+		case <-ctx.Done():
+			return
+		case results <- subscraping.Result{Type: subscraping.Subdomain, Source: source, Value: name}:
+		}
+	}
+}
+
+// writeNSEC3Hashes appends the NSEC3 hashes of the domain to the hash file, to be cracked offline
+func (r *Runner) writeNSEC3Hashes(domain string, hashes []zone.NSEC3Hash) {
+	if r.options.NSEC3Output == "" {
+		gologger.Info().Msgf("Collected %d NSEC3 hashes of %s, use -nsec3-output to save them\n", len(hashes), domain)
+		return
+	}
+
+	lines := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		lines = append(lines, hash.Format(domain))
+	}
+
+	r.outputMutex.Lock()
+	defer r.outputMutex.Unlock()
+
+	file, err := os.OpenFile(r.options.NSEC3Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		gologger.Error().Msgf("Could not open NSEC3 hash file %s: %s\n", r.options.NSEC3Output, err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		gologger.Error().Msgf("Could not write NSEC3 hashes to %s: %s\n", r.options.NSEC3Output, err)
+		return
+	}
+	gologger.Info().Msgf("Saved %d NSEC3 hashes of %s to %s\n", len(hashes), domain, r.options.NSEC3Output)
+}
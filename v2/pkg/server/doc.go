@@ -0,0 +1,3 @@
+// Package server exposes the subdomain enumeration as an HTTP API
+// where enumeration jobs are submitted, tracked and canceled.
+package server
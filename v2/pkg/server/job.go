@@ -0,0 +1,368 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	sliceutil "github.com/projectdiscovery/utils/slice"
+	"github.com/rs/xid"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/passive"
+	"github.com/YouChenJun/subfinder-plus/pkg/resolve"
+	"github.com/YouChenJun/subfinder-plus/pkg/runner"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// JobState is the state of an enumeration job
+type JobState string
+
+// States of an enumeration job
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobCompleted JobState = "completed"
+	JobFailed    JobState = "failed"
+	JobCanceled  JobState = "canceled"
+)
+
+// JobRequest contains the domains and options of an enumeration job
+type JobRequest struct {
+	Domains            []string `json:"domains"`
+	Sources            []string `json:"sources,omitempty"`
+	ExcludeSources     []string `json:"exclude_sources,omitempty"`
+	All                bool     `json:"all,omitempty"`
+	OnlyRecursive      bool     `json:"recursive,omitempty"`
+	RecursiveDepth     int      `json:"recursive_depth,omitempty"`
+	RecursiveBreadth   int      `json:"recursive_breadth,omitempty"`
+	Active             bool     `json:"active,omitempty"`
+	Threads            int      `json:"threads,omitempty"`
+	Timeout            int      `json:"timeout,omitempty"`
+	MaxEnumerationTime int      `json:"max_time,omitempty"`
+	DomainConcurrency  int      `json:"domain_concurrency,omitempty"`
+}
+
+// validate checks the request against the limits of the server,
+// filling the defaults of the missing options
+func (request *JobRequest) validate(limits *Options) error {
+	if len(request.Domains) == 0 {
+		return errors.New("no domains provided")
+	}
+
+	sourceNames := passive.SourceNames()
+	for _, sources := range [][]string{request.Sources, request.ExcludeSources} {
+		for _, source := range sources {
+			if !sliceutil.Contains(sourceNames, source) {
+				return fmt.Errorf("invalid source %s", source)
+			}
+		}
+	}
+	// the passive agent exits when no source is selected, catch it here
+	if !request.selectsSources() {
+		return errors.New("no sources selected for this search")
+	}
+
+	if request.RecursiveBreadth <= 0 {
+		request.RecursiveBreadth = 10
+	}
+	if request.Threads <= 0 {
+		request.Threads = 10
+	}
+	if request.Timeout <= 0 {
+		request.Timeout = 30
+	}
+	if request.MaxEnumerationTime <= 0 {
+		request.MaxEnumerationTime = 10
+	}
+
+	for _, limit := range []struct {
+		name         string
+		value, limit int
+	}{
+		{"recursive_depth", request.RecursiveDepth, limits.MaxRecursiveDepth},
+		{"recursive_breadth", request.RecursiveBreadth, limits.MaxRecursiveBreadth},
+		{"threads", request.Threads, limits.MaxThreads},
+		{"max_time", request.MaxEnumerationTime, limits.MaxEnumerationTime},
+		{"domain_concurrency", request.DomainConcurrency, limits.MaxDomainConcurrency},
+	} {
+		if limit.limit > 0 && limit.value > limit.limit {
+			return fmt.Errorf("%s must not exceed %d", limit.name, limit.limit)
+		}
+	}
+	return nil
+}
+
+// runnerOptions returns the options of the runner enumerating the request, normalized
+// and validated by the runner as the options given on the command line are
+func (request *JobRequest) runnerOptions(options *Options, providerApiKeys map[string][]string) (*runner.Options, error) {
+	runnerOptions := &runner.Options{
+		Domain:             append([]string(nil), request.Domains...),
+		Sources:            request.Sources,
+		ExcludeSources:     request.ExcludeSources,
+		All:                request.All,
+		OnlyRecursive:      request.OnlyRecursive,
+		RecursiveDepth:     request.RecursiveDepth,
+		RecursiveBreadth:   request.RecursiveBreadth,
+		RemoveWildcard:     request.Active,
+		Threads:            request.Threads,
+		Timeout:            request.Timeout,
+		MaxEnumerationTime: request.MaxEnumerationTime,
+		DomainConcurrency:  request.DomainConcurrency,
+		Resolvers:          options.Resolvers,
+		Proxy:              options.Proxy,
+		RateLimit:          options.RateLimit,
+		RateLimits:         options.RateLimits,
+		Retries:            options.Retries,
+		RetryDelay:         options.RetryDelay,
+		ProviderApiKeys:    providerApiKeys,
+		Output:             io.Discard,
+		Stream:             true,
+	}
+	if err := runnerOptions.Validate(); err != nil {
+		return nil, err
+	}
+	return runnerOptions, nil
+}
+
+// selectsSources returns true if the request selects at least one source
+func (request *JobRequest) selectsSources() bool {
+	for _, source := range passive.NewSources() {
+		switch {
+		case sliceutil.Contains(request.ExcludeSources, source.Name()):
+		case request.OnlyRecursive && !source.HasRecursiveSupport():
+		case request.All, sliceutil.Contains(request.Sources, source.Name()):
+			return true
+		case len(request.Sources) == 0 && source.IsDefault():
+			return true
+		}
+	}
+	return false
+}
+
+// JobResult is a subdomain found by an enumeration job
+type JobResult struct {
+	Host   string `json:"host"`
+	Input  string `json:"input"`
+	Source string `json:"source"`
+	Parent string `json:"parent,omitempty"`
+}
+
+// JobStatus is the state of a job as reported by the API
+type JobStatus struct {
+	ID       string     `json:"id"`
+	State    JobState   `json:"state"`
+	Request  JobRequest `json:"request"`
+	Results  int        `json:"results"`
+	Error    string     `json:"error,omitempty"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// SourceStatistics are the statistics of a source as reported by the API
+type SourceStatistics struct {
+	TimeTaken  string `json:"time_taken"`
+	Results    int    `json:"results"`
+	Unique     int    `json:"unique"`
+	Duplicates int    `json:"duplicates"`
+	OutOfScope int    `json:"out_of_scope"`
+	Filtered   int    `json:"filtered"`
+	Errors     int    `json:"errors"`
+	Retries    int    `json:"retries"`
+	Skipped    bool   `json:"skipped"`
+}
+
+// Job is an enumeration job submitted to the server
+type Job struct {
+	mutex sync.Mutex
+
+	id       string
+	request  JobRequest
+	options  *runner.Options
+	state    JobState
+	results  []JobResult
+	err      error
+	created  time.Time
+	started  time.Time
+	finished time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	runner *runner.Runner
+	// updated is closed and replaced every time the job changes
+	updated chan struct{}
+}
+
+func newJob(request JobRequest, options *runner.Options) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Job{
+		id:      xid.New().String(),
+		request: request,
+		options: options,
+		state:   JobQueued,
+		created: time.Now(),
+		ctx:     ctx,
+		cancel:  cancel,
+		updated: make(chan struct{}),
+	}
+}
+
+// notify wakes up the clients waiting for changes, the mutex must be held
+func (j *Job) notify() {
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+// run enumerates the domains of the job with a runner of its own
+func (j *Job) run() {
+	j.mutex.Lock()
+	if j.ctx.Err() != nil {
+		j.mutex.Unlock()
+		return
+	}
+	j.state = JobRunning
+	j.started = time.Now()
+	j.notify()
+	j.mutex.Unlock()
+
+	// The results are added to the job as the runner streams them
+	j.options.ResultCallback = j.addResult
+	// The logger is configured once by the options of the server, the output
+	// options of the runner are left unset so that it does not change it
+	enumerationRunner, err := runner.NewRunner(j.options)
+	if err == nil {
+		j.mutex.Lock()
+		j.runner = enumerationRunner
+		j.mutex.Unlock()
+
+		err = enumerationRunner.RunEnumerationWithCtx(j.ctx)
+		enumerationRunner.Close()
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.finished = time.Now()
+	switch {
+	case j.ctx.Err() != nil:
+		j.state = JobCanceled
+	case err != nil:
+		j.state = JobFailed
+		j.err = err
+	default:
+		j.state = JobCompleted
+	}
+	j.cancel()
+	j.notify()
+}
+
+// addResult is the result callback of the runner of the job
+func (j *Job) addResult(result *resolve.HostEntry) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.results = append(j.results, JobResult{Host: result.Host, Input: result.Domain, Source: result.Source, Parent: result.Parent})
+	j.notify()
+}
+
+// Cancel stops the job, whether it is queued or running
+func (j *Job) Cancel() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.state == JobQueued {
+		j.state = JobCanceled
+		j.finished = time.Now()
+		j.notify()
+	}
+	j.cancel()
+}
+
+// done returns true if the job will not change anymore
+func (j *Job) done() bool {
+	return j.state == JobCompleted || j.state == JobFailed || j.state == JobCanceled
+}
+
+// expired returns true if the job has been done for longer than the retention
+func (j *Job) expired(now time.Time, retention time.Duration) bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.done() && now.Sub(j.finished) > retention
+}
+
+// Status returns the current status of the job
+func (j *Job) Status() JobStatus {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	status := JobStatus{
+		ID:      j.id,
+		State:   j.state,
+		Request: j.request,
+		Results: len(j.results),
+		Created: j.created,
+	}
+	if j.err != nil {
+		status.Error = j.err.Error()
+	}
+	if !j.started.IsZero() {
+		status.Started = &j.started
+	}
+	if !j.finished.IsZero() {
+		status.Finished = &j.finished
+	}
+	return status
+}
+
+// Results returns the results of the job starting at offset, whether the
+// job is done and a channel closed when new results are available
+func (j *Job) Results(offset int) ([]JobResult, bool, <-chan struct{}) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var results []JobResult
+	if offset < len(j.results) {
+		results = append(results, j.results[offset:]...)
+	}
+	return results, j.done(), j.updated
+}
+
+// Statistics returns the source statistics of every domain enumerated by the job
+func (j *Job) Statistics() map[string]map[string]SourceStatistics {
+	j.mutex.Lock()
+	enumerationRunner := j.runner
+	j.mutex.Unlock()
+
+	statistics := make(map[string]map[string]SourceStatistics)
+	if enumerationRunner == nil {
+		return statistics
+	}
+	for _, domain := range j.request.Domains {
+		domainStatistics := enumerationRunner.GetDomainStatistics(domain)
+		if domainStatistics == nil {
+			continue
+		}
+		statistics[domain] = make(map[string]SourceStatistics, len(domainStatistics))
+		for source, stats := range domainStatistics {
+			statistics[domain][source] = newSourceStatistics(stats)
+		}
+	}
+	return statistics
+}
+
+func newSourceStatistics(stats subscraping.Statistics) SourceStatistics {
+	return SourceStatistics{
+		TimeTaken:  stats.TimeTaken.Round(time.Millisecond).String(),
+		Results:    stats.Results,
+		Unique:     stats.Unique(),
+		Duplicates: stats.Duplicates,
+		OutOfScope: stats.OutOfScope,
+		Filtered:   stats.Filtered,
+		Errors:     stats.Errors,
+		Retries:    stats.Retries,
+		Skipped:    stats.Skipped,
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/projectdiscovery/goflags"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/gologger/formatter"
+	"github.com/projectdiscovery/gologger/levels"
+	folderutil "github.com/projectdiscovery/utils/folder"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/runner"
+)
+
+var (
+	configDir                     = folderutil.AppConfigDirOrDefault(".", "subfinder")
+	defaultConfigLocation         = filepath.Join(configDir, "serve-config.yaml")
+	defaultProviderConfigLocation = filepath.Join(configDir, "provider-config.yaml")
+	defaultCustomSourcesLocation  = filepath.Join(configDir, "custom-sources.yaml")
+)
+
+// Options contains the configuration options of the API server
+type Options struct {
+	ListenAddress        string               // ListenAddress is the address the API server listens on
+	JobConcurrency       int                  // JobConcurrency is the number of jobs running at the same time
+	QueueSize            int                  // QueueSize is the maximum number of jobs waiting to run
+	JobRetention         time.Duration        // JobRetention is how long the finished jobs are kept, forever if zero
+	MaxRecursiveDepth    int                  // MaxRecursiveDepth is the maximum recursive depth of a job, unlimited if zero
+	MaxRecursiveBreadth  int                  // MaxRecursiveBreadth is the maximum recursive breadth of a job, unlimited if zero
+	MaxThreads           int                  // MaxThreads is the maximum number of resolution threads of a job, unlimited if zero
+	MaxEnumerationTime   int                  // MaxEnumerationTime is the maximum enumeration time of a job in minutes, unlimited if zero
+	MaxDomainConcurrency int                  // MaxDomainConcurrency is the maximum number of domains a job enumerates at once, unlimited if zero
+	ProviderConfig       string               // ProviderConfig contains the location of the provider config file
+	CustomSources        string               // CustomSources contains the location of the file declaring HTTP-JSON and plugin sources
+	Proxy                string               // HTTP proxy
+	RateLimit            int                  // Global maximum number of HTTP requests to send per second
+	RateLimits           goflags.RateLimitMap // Maximum number of HTTP requests to send per second
+	Retries              int                  // Retries is the number of times a failed HTTP request of a source is sent again
+	RetryDelay           time.Duration        // RetryDelay is the backoff before the first retry of a request
+	Resolvers            goflags.StringSlice  // Resolvers is the comma-separated resolvers to use for enumeration
+	Verbose              bool                 // Verbose flag indicates whether to show verbose output or not
+	NoColor              bool                 // NoColor disables the colored output
+	Silent               bool                 // Silent suppresses any extra text
+}
+
+// ParseOptions parses the command line flags of the serve command
+func ParseOptions(args []string) *Options {
+	options := &Options{}
+
+	flagSet := goflags.NewFlagSet()
+	flagSet.SetDescription(`Runs subfinder as an HTTP API server to submit and track enumeration jobs.`)
+	flagSet.SetConfigFilePath(defaultConfigLocation)
+
+	flagSet.CreateGroup("server", "Server",
+		flagSet.StringVarP(&options.ListenAddress, "listen", "l", "127.0.0.1:8080", "address for the API server to listen on"),
+		flagSet.IntVarP(&options.JobConcurrency, "job-concurrency", "jc", 2, "number of jobs to run concurrently"),
+		flagSet.IntVarP(&options.QueueSize, "queue-size", "qs", 100, "maximum number of queued jobs"),
+		flagSet.DurationVarP(&options.JobRetention, "job-retention", "jr", time.Hour, "time to keep the finished jobs and their results (0 to keep them forever)"),
+	)
+
+	flagSet.CreateGroup("job-limits", "Job-Limits",
+		flagSet.IntVarP(&options.MaxRecursiveDepth, "max-recursive-depth", "mrd", 3, "maximum recursive depth of a job (0 for no limit)"),
+		flagSet.IntVarP(&options.MaxRecursiveBreadth, "max-recursive-breadth", "mrb", 100, "maximum number of subdomains a job enumerates recursively per depth (0 for no limit)"),
+		flagSet.IntVarP(&options.MaxThreads, "max-threads", "mt", 50, "maximum number of resolution threads of a job (0 for no limit)"),
+		flagSet.IntVarP(&options.MaxEnumerationTime, "max-job-time", "mjt", 30, "maximum enumeration time of a job in minutes (0 for no limit)"),
+		flagSet.IntVarP(&options.MaxDomainConcurrency, "max-domain-concurrency", "mdc", 4, "maximum number of domains a job enumerates concurrently (0 for no limit)"),
+	)
+
+	flagSet.CreateGroup("configuration", "Configuration",
+		flagSet.StringVarP(&options.ProviderConfig, "provider-config", "pc", defaultProviderConfigLocation, "provider config file"),
+		flagSet.StringVarP(&options.CustomSources, "custom-sources", "csf", defaultCustomSourcesLocation, "file declaring custom http-json and plugin sources"),
+		flagSet.StringVar(&options.Proxy, "proxy", "", "http proxy to use with subfinder"),
+		flagSet.StringSliceVar(&options.Resolvers, "r", nil, "comma separated list of resolvers to use", goflags.NormalizedStringSliceOptions),
+	)
+
+	flagSet.CreateGroup("rate-limit", "Rate-limit",
+		flagSet.IntVarP(&options.RateLimit, "rate-limit", "rl", 0, "maximum number of http requests to send per second (global)"),
+		flagSet.RateLimitMapVarP(&options.RateLimits, "rate-limits", "rls", runner.DefaultRateLimits, "maximum number of http requests to send per second for providers in key=value format (-rls hackertarget=10/m)", goflags.NormalizedStringSliceOptions),
+		flagSet.IntVar(&options.Retries, "retries", 2, "number of times a failed http request to a provider is retried"),
+		flagSet.DurationVarP(&options.RetryDelay, "retry-delay", "rtd", time.Second, "backoff before the first retry of a failed http request, doubled for every following one"),
+	)
+
+	flagSet.CreateGroup("debug", "Debug",
+		flagSet.BoolVar(&options.Silent, "silent", false, "show only errors in output"),
+		flagSet.BoolVar(&options.Verbose, "v", false, "show verbose output"),
+		flagSet.BoolVarP(&options.NoColor, "no-color", "nc", false, "disable color in output"),
+	)
+
+	if err := flagSet.Parse(args...); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	options.configureOutput()
+
+	if err := runner.LoadCustomSources(options.CustomSources, &options.RateLimits); err != nil {
+		gologger.Fatal().Msgf("Could not load custom sources from %s: %s\n", options.CustomSources, err)
+	}
+
+	if err := options.validateOptions(); err != nil {
+		gologger.Fatal().Msgf("Program exiting: %s\n", err)
+	}
+	return options
+}
+
+// validateOptions validates the configuration options passed
+func (options *Options) validateOptions() error {
+	if options.ListenAddress == "" {
+		return errors.New("listen address cannot be empty")
+	}
+	if options.JobConcurrency <= 0 {
+		return errors.New("job concurrency must be greater than zero")
+	}
+	if options.QueueSize <= 0 {
+		return errors.New("queue size must be greater than zero")
+	}
+	if options.JobRetention < 0 {
+		return errors.New("job retention must not be negative")
+	}
+	if options.MaxRecursiveDepth < 0 || options.MaxRecursiveBreadth < 0 || options.MaxThreads < 0 || options.MaxEnumerationTime < 0 || options.MaxDomainConcurrency < 0 {
+		return errors.New("job limits must not be negative")
+	}
+	if options.Retries < 0 {
+		return errors.New("retries must not be negative")
+	}
+	return nil
+}
+
+// configureOutput configures the output on the screen
+func (options *Options) configureOutput() {
+	if options.Verbose {
+		gologger.DefaultLogger.SetMaxLevel(levels.LevelVerbose)
+	}
+	if options.NoColor {
+		gologger.DefaultLogger.SetFormatter(formatter.NewCLI(true))
+	}
+	if options.Silent {
+		gologger.DefaultLogger.SetMaxLevel(levels.LevelError)
+	}
+}
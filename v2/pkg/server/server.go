@@ -0,0 +1,248 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+	fileutil "github.com/projectdiscovery/utils/file"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/runner"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// maxRequestSize is the maximum size of the body of a job request
+const maxRequestSize = 1 << 20
+
+// Server is the HTTP API server running enumeration jobs
+type Server struct {
+	options *Options
+	// providerApiKeys are loaded once at startup and shared by every job
+	providerApiKeys map[string][]string
+
+	mutex sync.RWMutex
+	jobs  map[string]*Job
+	queue chan *Job
+}
+
+// New creates a new API server and starts its job workers
+func New(options *Options) (*Server, error) {
+	providerApiKeys := make(map[string][]string)
+	if fileutil.FileExists(options.ProviderConfig) {
+		gologger.Info().Msgf("Loading provider config from %s", options.ProviderConfig)
+		var err error
+		if providerApiKeys, err = runner.UnmarshalFrom(options.ProviderConfig); err != nil {
+			return nil, fmt.Errorf("could not read provider config: %s", err)
+		}
+	}
+
+	server := &Server{
+		options:         options,
+		providerApiKeys: providerApiKeys,
+		jobs:            make(map[string]*Job),
+		queue:           make(chan *Job, options.QueueSize),
+	}
+	for i := 0; i < options.JobConcurrency; i++ {
+		go server.worker()
+	}
+	return server, nil
+}
+
+// Start listens on the configured address and serves the API
+func (s *Server) Start() error {
+	gologger.Info().Msgf("Listening on %s", s.options.ListenAddress)
+	return http.ListenAndServe(s.options.ListenAddress, s)
+}
+
+// worker runs the queued jobs one at a time
+func (s *Server) worker() {
+	for job := range s.queue {
+		job.run()
+	}
+}
+
+// ServeHTTP routes the API requests:
+//
+//	POST   /jobs                  submits a job
+//	GET    /jobs                  lists the jobs
+//	GET    /jobs/{id}             returns the status of a job
+//	DELETE /jobs/{id}             cancels a job
+//	GET    /jobs/{id}/results     returns the results, streamed as NDJSON if requested
+//	GET    /jobs/{id}/statistics  returns the source statistics
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "jobs" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodPost:
+			s.handleSubmit(w, r)
+		case http.MethodGet:
+			s.handleList(w)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		}
+		return
+	}
+
+	job := s.job(parts[1])
+	if job == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", parts[1]))
+		return
+	}
+
+	resource := ""
+	if len(parts) == 3 {
+		resource = parts[2]
+	}
+	switch {
+	case resource == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, job.Status())
+	case resource == "" && r.Method == http.MethodDelete:
+		job.Cancel()
+		writeJSON(w, http.StatusOK, job.Status())
+	case resource == "results" && r.Method == http.MethodGet:
+		s.handleResults(w, r, job)
+	case resource == "statistics" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, job.Statistics())
+	case resource == "" || resource == "results" || resource == "statistics":
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (s *Server) job(id string) *Job {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.jobs[id]
+}
+
+// removeExpiredJobs forgets the jobs finished for longer than the retention
+// of the server, the mutex must be held
+func (s *Server) removeExpiredJobs() {
+	if s.options.JobRetention <= 0 {
+		return
+	}
+	now := time.Now()
+	for id, job := range s.jobs {
+		if job.expired(now, s.options.JobRetention) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var request JobRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if err := jsoniter.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid job request: %s", err))
+		return
+	}
+	if err := request.validate(s.options); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	runnerOptions, err := request.runnerOptions(s.options, s.providerApiKeys)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// The status of the job shows the domains as the runner normalized them
+	request.Domains = runnerOptions.Domain
+
+	job := newJob(request, runnerOptions)
+	select {
+	case s.queue <- job:
+	default:
+		writeError(w, http.StatusServiceUnavailable, errors.New("job queue is full"))
+		return
+	}
+
+	s.mutex.Lock()
+	s.removeExpiredJobs()
+	s.jobs[job.id] = job
+	s.mutex.Unlock()
+
+	gologger.Verbose().Msgf("Queued job %s for %s", job.id, strings.Join(request.Domains, ", "))
+	writeJSON(w, http.StatusCreated, job.Status())
+}
+
+func (s *Server) handleList(w http.ResponseWriter) {
+	s.mutex.Lock()
+	s.removeExpiredJobs()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, job.Status())
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Created.Before(statuses[j].Created)
+	})
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+// handleResults returns the results found so far as a JSON array or,
+// when NDJSON is requested, streams them until the job is done
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request, job *Job) {
+	if r.URL.Query().Get("format") != "ndjson" && !strings.Contains(r.Header.Get("Accept"), ndjsonContentType) {
+		results, _, _ := job.Results(0)
+		if results == nil {
+			results = []JobResult{}
+		}
+		writeJSON(w, http.StatusOK, results)
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := jsoniter.NewEncoder(w)
+
+	offset := 0
+	for {
+		results, done, updated := job.Results(offset)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return
+			}
+		}
+		offset += len(results)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if done {
+			return
+		}
+
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := jsoniter.NewEncoder(w).Encode(value); err != nil {
+		gologger.Warning().Msgf("Could not write response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
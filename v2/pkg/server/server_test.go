@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/passive"
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+type fakeSource struct{}
+
+func (s *fakeSource) Run(_ context.Context, domain string, _ *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result, 1)
+	results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: "www." + domain}
+	close(results)
+	return results
+}
+
+func (s *fakeSource) Name() string {
+	return "fakesource"
+}
+
+func (s *fakeSource) IsDefault() bool {
+	return false
+}
+
+func (s *fakeSource) HasRecursiveSupport() bool {
+	return false
+}
+
+func (s *fakeSource) NeedsKey() bool {
+	return false
+}
+
+func (s *fakeSource) AddApiKeys(_ []string) {}
+
+func init() {
+	if err := passive.RegisterSource(func() subscraping.Source { return &fakeSource{} }); err != nil {
+		panic(err)
+	}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server, err := New(&Options{JobConcurrency: 1, QueueSize: 10, MaxRecursiveDepth: 2})
+	require.Nil(t, err)
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+func submitJob(t *testing.T, url, request string) (int, JobStatus) {
+	resp, err := http.Post(url+"/jobs", "application/json", strings.NewReader(request))
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	var status JobStatus
+	require.Nil(t, jsoniter.NewDecoder(resp.Body).Decode(&status))
+	return resp.StatusCode, status
+}
+
+func getJSON(t *testing.T, url string, value interface{}) {
+	resp, err := http.Get(url)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Nil(t, jsoniter.NewDecoder(resp.Body).Decode(value))
+}
+
+func TestServerJob(t *testing.T) {
+	httpServer := newTestServer(t)
+
+	// The domains are normalized as the domains given on the command line
+	code, status := submitJob(t, httpServer.URL, `{"domains":["https://*.Example.COM"],"sources":["fakesource"]}`)
+	require.Equal(t, http.StatusCreated, code)
+	require.NotEmpty(t, status.ID)
+	assert.Equal(t, []string{"example.com"}, status.Request.Domains)
+
+	// the NDJSON stream ends once the job is done
+	resp, err := http.Get(httpServer.URL + "/jobs/" + status.ID + "/results?format=ndjson")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, ndjsonContentType, resp.Header.Get("Content-Type"))
+
+	var streamed []JobResult
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var result JobResult
+		require.Nil(t, jsoniter.Unmarshal(scanner.Bytes(), &result))
+		streamed = append(streamed, result)
+	}
+	require.Nil(t, scanner.Err())
+	assert.Equal(t, []JobResult{{Host: "www.example.com", Input: "example.com", Source: "fakesource"}}, streamed)
+
+	require.Eventually(t, func() bool {
+		getJSON(t, httpServer.URL+"/jobs/"+status.ID, &status)
+		return status.State == JobCompleted
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, 1, status.Results)
+
+	var results []JobResult
+	getJSON(t, httpServer.URL+"/jobs/"+status.ID+"/results", &results)
+	assert.Equal(t, streamed, results)
+
+	var statistics map[string]map[string]SourceStatistics
+	getJSON(t, httpServer.URL+"/jobs/"+status.ID+"/statistics", &statistics)
+	assert.Equal(t, 1, statistics["example.com"]["fakesource"].Results)
+
+	var jobs []JobStatus
+	getJSON(t, httpServer.URL+"/jobs", &jobs)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, status.ID, jobs[0].ID)
+}
+
+func TestServerInvalidJob(t *testing.T) {
+	httpServer := newTestServer(t)
+
+	for _, request := range []string{
+		`{"domains":[]}`,
+		`{"domains":["example.com"],"sources":["unknownsource"]}`,
+		`{"domains":["example.com"],"sources":["fakesource"],"exclude_sources":["fakesource"]}`,
+		`{"domains":["example.com"],"sources":["fakesource"],"recursive_depth":3}`,
+		`{"domains":["example.com"],"sources":["fakesource"],"recursive_depth":-1}`,
+		`{"domains":["` + strings.Repeat("a", maxRequestSize) + `.com"]}`,
+		`not json`,
+	} {
+		resp, err := http.Post(httpServer.URL+"/jobs", "application/json", strings.NewReader(request))
+		require.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, request)
+	}
+
+	resp, err := http.Get(httpServer.URL + "/jobs/unknown")
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServerJobRetention(t *testing.T) {
+	expired, recent, running := newJob(JobRequest{}, nil), newJob(JobRequest{}, nil), newJob(JobRequest{}, nil)
+	expired.state, expired.finished = JobCompleted, time.Now().Add(-2*time.Hour)
+	recent.state, recent.finished = JobCanceled, time.Now()
+	running.state = JobRunning
+
+	server := &Server{
+		options: &Options{JobRetention: time.Hour},
+		jobs:    map[string]*Job{expired.id: expired, recent.id: recent, running.id: running},
+	}
+	server.removeExpiredJobs()
+	assert.Nil(t, server.job(expired.id))
+	assert.NotNil(t, server.job(recent.id))
+	assert.NotNil(t, server.job(running.id))
+}
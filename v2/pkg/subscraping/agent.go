@@ -1,9 +1,11 @@
 package subscraping
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/corpix/uarand"
 	"github.com/projectdiscovery/ratelimit"
+	"golang.org/x/net/proxy"
 
 	"github.com/projectdiscovery/gologger"
 )
@@ -46,7 +49,10 @@ func NewSession(domain string, proxy string, multiRateLimiter *ratelimit.MultiLi
 		Timeout:   time.Duration(timeout) * time.Second,
 	}
 	//这里把resp保存的路径封装到这里
-	session := &Session{Client: client, RespFileDirectory: RespFileDirectory}
+	session := &Session{Client: client, RespFileDirectory: RespFileDirectory, Statistics: NewStatisticsCollector(), RetryPolicy: DefaultRetryPolicy}
+	if Transport.Proxy != nil {
+		session.proxyURL, _ = url.Parse(proxy)
+	}
 
 	// Initiate rate limit instance
 	session.MultiRateLimiter = multiRateLimiter
@@ -79,7 +85,55 @@ func (s *Session) SimplePost(ctx context.Context, postURL, contentType string, b
 }
 
 // HTTPRequest makes any HTTP request to a URL with extended parameters
+// and retries it according to the retry policy of the source
 func (s *Session) HTTPRequest(ctx context.Context, method, requestURL, cookies string, headers map[string]string, body io.Reader, basicAuth BasicAuth) (*http.Response, error) {
+	// The body is read beforehand to be sent again by the retries
+	var content []byte
+	if body != nil {
+		var err error
+		if content, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	sourceName := ctx.Value(CtxSourceArg).(string)
+	policy := s.RetryPolicy
+	if sourcePolicy, ok := s.RetryPolicies[sourceName]; ok {
+		policy = sourcePolicy
+	}
+	for attempt := 0; ; attempt++ {
+		var requestBody io.Reader
+		if body != nil {
+			requestBody = bytes.NewReader(content)
+		}
+		req, err := newRequest(ctx, method, requestURL, cookies, headers, requestBody, basicAuth)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.MultiRateLimiter.Take(sourceName); err != nil {
+			return nil, err
+		}
+		// Only the failures of the request itself are worth sending it again
+		resp, err := httpRequestWrapper(s.Client, req)
+
+		delay, retry := policy.backoff(attempt, resp, err)
+		if !retry || ctx.Err() != nil {
+			return resp, err
+		}
+		s.DiscardHTTPResponse(resp)
+		s.Statistics.AddRetry(sourceName)
+		gologger.Debug().Msgf("Retrying request to %s in %s: %s\n", requestURL, delay.Round(time.Millisecond), err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// newRequest creates the request with the headers sent to the sources
+func newRequest(ctx context.Context, method, requestURL, cookies string, headers map[string]string, body io.Reader, basicAuth BasicAuth) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
 	if err != nil {
 		return nil, err
@@ -101,14 +155,77 @@ func (s *Session) HTTPRequest(ctx context.Context, method, requestURL, cookies s
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	return req, nil
+}
 
-	sourceName := ctx.Value(CtxSourceArg).(string)
-	mrlErr := s.MultiRateLimiter.Take(sourceName)
-	if mrlErr != nil {
-		return nil, mrlErr
+// Dial opens a TCP connection to the address, through the proxy of the session
+// if it has one. SOCKS5 proxies are dialed directly, HTTP proxies are asked to
+// tunnel the connection with a CONNECT request.
+func (s *Session) Dial(ctx context.Context, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: s.Client.Timeout}
+	if s.proxyURL == nil {
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	switch s.proxyURL.Scheme {
+	case "socks5", "socks5h":
+		proxyDialer, err := proxy.FromURL(s.proxyURL, dialer)
+		if err != nil {
+			return nil, err
+		}
+		return proxyDialer.(proxy.ContextDialer).DialContext(ctx, "tcp", address)
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %s", s.proxyURL.Scheme)
+	}
+
+	proxyAddress := s.proxyURL.Host
+	if s.proxyURL.Port() == "" {
+		port := "80"
+		if s.proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddress = net.JoinHostPort(s.proxyURL.Hostname(), port)
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, err
+	}
+	if s.proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: s.proxyURL.Hostname()})
 	}
 
-	return httpRequestWrapper(s.Client, req)
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if dialer.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(dialer.Timeout))
+	}
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := s.proxyURL.User; user != nil {
+		password, _ := user.Password()
+		request.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response, err := http.ReadResponse(bufio.NewReader(conn), request)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused to connect to %s: %s", address, response.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
 }
 
 // DiscardHTTPResponse discards the response content by demand
@@ -123,9 +240,9 @@ func (s *Session) DiscardHTTPResponse(response *http.Response) {
 	}
 }
 
-// Close the session
+// Close the session. The rate limiter is owned by the
+// caller which created the session and is not stopped.
 func (s *Session) Close() {
-	s.MultiRateLimiter.Stop()
 	s.Client.CloseIdleConnections()
 }
 
@@ -0,0 +1,46 @@
+package subscraping
+
+import "time"
+
+// Metadata contains the details a source returned along with a subdomain.
+// Every field is optional since most sources only know some of them.
+type Metadata struct {
+	FirstSeen    time.Time // FirstSeen is when the source first observed the subdomain
+	LastSeen     time.Time // LastSeen is when the source last observed the subdomain
+	IPs          []string  // IPs contains the addresses the subdomain was observed on
+	Ports        []int     // Ports contains the open ports observed on the subdomain
+	Fingerprints []string  // Fingerprints contains the SHA-256 fingerprints of the certificates naming the subdomain
+}
+
+// Merge adds the details of other to the metadata, widening the
+// first and last seen range and adding the missing values
+func (m *Metadata) Merge(other *Metadata) {
+	if other == nil {
+		return
+	}
+	if !other.FirstSeen.IsZero() && (m.FirstSeen.IsZero() || other.FirstSeen.Before(m.FirstSeen)) {
+		m.FirstSeen = other.FirstSeen
+	}
+	if other.LastSeen.After(m.LastSeen) {
+		m.LastSeen = other.LastSeen
+	}
+	m.IPs = appendMissing(m.IPs, other.IPs...)
+	m.Ports = appendMissing(m.Ports, other.Ports...)
+	m.Fingerprints = appendMissing(m.Fingerprints, other.Fingerprints...)
+}
+
+func appendMissing[T comparable](values []T, newValues ...T) []T {
+	for _, newValue := range newValues {
+		found := false
+		for _, value := range values {
+			if value == newValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, newValue)
+		}
+	}
+	return values
+}
@@ -0,0 +1,27 @@
+package subscraping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataMerge(t *testing.T) {
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	metadata := &Metadata{}
+	metadata.Merge(nil)
+	metadata.Merge(&Metadata{LastSeen: first, IPs: []string{"1.1.1.1"}, Ports: []int{443}})
+	metadata.Merge(&Metadata{FirstSeen: first, LastSeen: last, IPs: []string{"1.1.1.1", "2.2.2.2"}, Fingerprints: []string{"abcd"}})
+	metadata.Merge(&Metadata{FirstSeen: last, Ports: []int{443, 80}})
+
+	assert.Equal(t, &Metadata{
+		FirstSeen:    first,
+		LastSeen:     last,
+		IPs:          []string{"1.1.1.1", "2.2.2.2"},
+		Ports:        []int{443, 80},
+		Fingerprints: []string{"abcd"},
+	}, metadata)
+}
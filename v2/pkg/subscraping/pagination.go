@@ -0,0 +1,164 @@
+package subscraping
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// PaginationStyle is the way the pages of results of a source follow each other
+type PaginationStyle int
+
+// Pagination styles of the sources
+const (
+	// PageNumber pages are requested by their number, counting from the start of the paginator
+	PageNumber PaginationStyle = iota
+	// Offset pages are requested by the offset of their first result, counting from the start of the paginator
+	Offset
+	// Cursor pages are requested with the cursor returned along with the previous page
+	Cursor
+	// LinkHeader pages are requested at the next URL of the Link header of the previous page
+	LinkHeader
+)
+
+// DefaultMaxPages is the number of pages requested from a source which does not cap them itself
+const DefaultMaxPages = 20
+
+// UnlimitedPages lets a source request all of its pages unless the session caps them
+const UnlimitedPages = math.MaxInt
+
+// PageLimits caps the pages requested from a source and the results read from them.
+// A zero value leaves the corresponding cap unset.
+type PageLimits struct {
+	MaxPages   int // MaxPages is the maximum number of pages requested
+	MaxResults int // MaxResults is the number of results after which no more page is requested
+}
+
+// override returns the limits with the values set in other replacing its own
+func (l PageLimits) override(other PageLimits) PageLimits {
+	if other.MaxPages > 0 {
+		l.MaxPages = other.MaxPages
+	}
+	if other.MaxResults > 0 {
+		l.MaxResults = other.MaxResults
+	}
+	return l
+}
+
+// Paginator describes how the pages of results of a source are requested
+type Paginator struct {
+	Style PaginationStyle
+	// Start is the number of the first page, or the offset of its first result
+	Start int
+	// Size is the number of results per page, the offset moves by it from a page to the next.
+	// The offset moves by the results of the page when it is zero.
+	Size int
+	// PageLimits are the default limits of the source, the limits of the session take precedence
+	PageLimits
+}
+
+// Page is the position of the page to fetch
+type Page struct {
+	Index  int    // Index is the number of pages fetched before this one
+	Number int    // Number is the number of the page, for the PageNumber style
+	Offset int    // Offset is the offset of the first result of the page, for the Offset style
+	Cursor string // Cursor is the cursor or the URL of the page, empty for the first page
+}
+
+// PageResponse describes the page fetched
+type PageResponse struct {
+	// Results is the number of results read from the page, no more page is requested when it is zero
+	Results int
+	// Total is the number of results of the query if the source reports it
+	Total int
+	// More reports that the source has more pages, for the sources telling so instead of reporting a total
+	More bool
+	// Next is the cursor of the next page, empty on the last page
+	Next string
+	// Header is the header of the response, the next page is read from its Link header for the LinkHeader style
+	Header http.Header
+}
+
+// Paginate fetches the pages of results of the source one after the other until the last
+// page, the page or result limits of the source, or the cancellation of the context. It stops
+// at the first error, which is returned unless the context was cancelled. Every request of the
+// session takes the rate limit of the source, which paces the pages without any pause in between.
+func (s *Session) Paginate(ctx context.Context, paginator Paginator, fetch func(page Page) (PageResponse, error)) error {
+	limits := paginator.PageLimits
+	if source, ok := ctx.Value(CtxSourceArg).(string); ok {
+		limits = limits.override(s.PageLimits[source])
+	}
+	if limits.MaxPages <= 0 {
+		limits.MaxPages = DefaultMaxPages
+	}
+
+	page := Page{Number: paginator.Start, Offset: paginator.Start}
+	results := 0
+	for ; page.Index < limits.MaxPages; page.Index++ {
+		// The pages fetched until the cancellation are what the source found
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		response, err := fetch(page)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		results += response.Results
+		if response.Results == 0 || (limits.MaxResults > 0 && results >= limits.MaxResults) {
+			return nil
+		}
+
+		switch paginator.Style {
+		case PageNumber, Offset:
+			if !response.More && results >= response.Total {
+				return nil
+			}
+			page.Number++
+			if paginator.Size > 0 {
+				page.Offset += paginator.Size
+			} else {
+				page.Offset += response.Results
+			}
+		case Cursor:
+			if response.Next == "" {
+				return nil
+			}
+			page.Cursor = response.Next
+		case LinkHeader:
+			page.Cursor = NextLink(response.Header)
+			if page.Cursor == "" {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// NextLink returns the URL of the next relation of the Link header, empty if it has none
+func NextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, relations, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, relation := range strings.Fields(strings.Trim(relations, `"`)) {
+					if strings.EqualFold(relation, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
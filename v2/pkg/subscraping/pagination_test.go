@@ -0,0 +1,139 @@
+package subscraping
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	session := &Session{PageLimits: map[string]PageLimits{"capped": {MaxPages: 2}, "counted": {MaxResults: 25}}}
+	paginate := func(source string, paginator Paginator, responses func(page Page) PageResponse) ([]Page, error) {
+		var pages []Page
+		ctx := context.WithValue(context.Background(), CtxSourceArg, source)
+		err := session.Paginate(ctx, paginator, func(page Page) (PageResponse, error) {
+			pages = append(pages, page)
+			return responses(page), nil
+		})
+		return pages, err
+	}
+
+	// The pages follow each other until the total is reached
+	pages, err := paginate("numbered", Paginator{Style: PageNumber, Start: 1}, func(page Page) PageResponse {
+		return PageResponse{Results: 10, Total: 25}
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []Page{{Index: 0, Number: 1, Offset: 1}, {Index: 1, Number: 2, Offset: 11}, {Index: 2, Number: 3, Offset: 21}}, pages)
+
+	// The offset moves by the page size, the source tells whether more pages follow
+	pages, err = paginate("offset", Paginator{Style: Offset, Size: 100}, func(page Page) PageResponse {
+		return PageResponse{Results: 100, More: page.Offset < 200}
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []int{0, 100, 200}, offsets(pages))
+
+	// The limits of the session replace the limits of the source
+	pages, err = paginate("capped", Paginator{Style: Cursor, PageLimits: PageLimits{MaxPages: 5}}, func(page Page) PageResponse {
+		return PageResponse{Results: 1, Next: page.Cursor + "n"}
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []Page{{Index: 0}, {Index: 1, Cursor: "n"}}, pages)
+
+	pages, err = paginate("counted", Paginator{Style: Cursor}, func(page Page) PageResponse {
+		return PageResponse{Results: 10, Next: "next"}
+	})
+	require.Nil(t, err)
+	assert.Len(t, pages, 3)
+
+	// Sources without limits stop after the default number of pages
+	pages, err = paginate("unlimited", Paginator{Style: PageNumber}, func(page Page) PageResponse {
+		return PageResponse{Results: 1, More: true}
+	})
+	require.Nil(t, err)
+	assert.Len(t, pages, DefaultMaxPages)
+
+	// Sources requesting all of their pages are only capped by the session
+	pages, err = paginate("capped", Paginator{Style: PageNumber, PageLimits: PageLimits{MaxPages: UnlimitedPages}}, func(page Page) PageResponse {
+		return PageResponse{Results: 1, More: page.Index < 30}
+	})
+	require.Nil(t, err)
+	assert.Len(t, pages, 2)
+	pages, err = paginate("unlimited", Paginator{Style: PageNumber, PageLimits: PageLimits{MaxPages: UnlimitedPages}}, func(page Page) PageResponse {
+		return PageResponse{Results: 1, More: page.Index < 30}
+	})
+	require.Nil(t, err)
+	assert.Len(t, pages, 31)
+
+	// An empty page ends the pagination
+	pages, err = paginate("empty", Paginator{Style: Cursor}, func(page Page) PageResponse {
+		return PageResponse{Next: "next"}
+	})
+	require.Nil(t, err)
+	assert.Len(t, pages, 1)
+
+	// The next pages are read from the Link header
+	pages, err = paginate("linked", Paginator{Style: LinkHeader}, func(page Page) PageResponse {
+		header := http.Header{}
+		if page.Index < 2 {
+			header.Set("Link", `<https://api.example.com/?page=`+strconv.Itoa(page.Index+2)+`>; rel="next"`)
+		}
+		return PageResponse{Results: 1, Header: header}
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []Page{{Index: 0}, {Index: 1, Cursor: "https://api.example.com/?page=2"}, {Index: 2, Cursor: "https://api.example.com/?page=3"}}, pages)
+}
+
+func TestPaginateError(t *testing.T) {
+	session := &Session{}
+	fetched := 0
+	err := session.Paginate(context.Background(), Paginator{Style: PageNumber}, func(page Page) (PageResponse, error) {
+		fetched++
+		if page.Index == 1 {
+			return PageResponse{}, errors.New("quota exceeded")
+		}
+		return PageResponse{Results: 1, More: true}, nil
+	})
+	assert.EqualError(t, err, "quota exceeded")
+	assert.Equal(t, 2, fetched)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = session.Paginate(ctx, Paginator{Style: PageNumber}, func(page Page) (PageResponse, error) {
+		t.Fatal("page fetched with a cancelled context")
+		return PageResponse{}, nil
+	})
+	assert.Nil(t, err)
+
+	// The failure of a request cut off by the cancellation is not an error of the source
+	ctx, cancel = context.WithCancel(context.Background())
+	err = session.Paginate(ctx, Paginator{Style: PageNumber}, func(page Page) (PageResponse, error) {
+		cancel()
+		return PageResponse{}, context.Canceled
+	})
+	assert.Nil(t, err)
+}
+
+func TestNextLink(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, "", NextLink(header))
+
+	header.Add("Link", `<https://api.example.com/items?page=1>; rel="prev", <https://api.example.com/items?page=3>; rel="next"`)
+	assert.Equal(t, "https://api.example.com/items?page=3", NextLink(header))
+
+	header.Set("Link", `<https://api.example.com/items?page=9>; rel="last"`)
+	header.Add("Link", `<https://api.example.com/items?cursor=abc>; title="more"; rel="next last"`)
+	assert.Equal(t, "https://api.example.com/items?cursor=abc", NextLink(header))
+}
+
+func offsets(pages []Page) []int {
+	var offsets []int
+	for _, page := range pages {
+		offsets = append(offsets, page.Offset)
+	}
+	return offsets
+}
@@ -0,0 +1,81 @@
+package subscraping
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how the failed requests of a source are sent again. The
+// requests failing with a network error or with a 429, 500, 502, 503 or 504 status
+// are retried, after the delay asked by the Retry-After or X-RateLimit-Reset header
+// of the response or else after an exponential backoff with jitter.
+type RetryPolicy struct {
+	MaxRetries int           // MaxRetries is the number of times a failed request is sent again
+	BaseDelay  time.Duration // BaseDelay is the backoff before the first retry, doubled for every following one
+	MaxDelay   time.Duration // MaxDelay caps the backoff, a request the server asks to delay longer is not retried
+}
+
+// DefaultRetryPolicy is the retry policy of the sessions
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+// backoff returns how long to wait before retrying the request after
+// its given attempt failed, false if it should not be retried
+func (p RetryPolicy) backoff(attempt int, response *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+	if response == nil {
+		return p.jitter(attempt), err != nil
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+	if delay, ok := serverDelay(response.Header, time.Now()); ok {
+		return delay, delay <= p.MaxDelay
+	}
+	return p.jitter(attempt), true
+}
+
+// jitter returns a random delay between the half and the whole of the exponential backoff
+// of the attempt. The requests of a policy without a base delay are retried right away.
+func (p RetryPolicy) jitter(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.MaxDelay
+	if backoff := p.BaseDelay << attempt; attempt < 32 && backoff > 0 && backoff < p.MaxDelay {
+		delay = backoff
+	}
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// serverDelay returns the delay asked by the Retry-After header of the response, in
+// seconds or as a date, or by its X-RateLimit-Reset header, in seconds or as a unix time
+func serverDelay(header http.Header, now time.Time) (time.Duration, bool) {
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.ParseInt(retryAfter, 10, 64); err == nil {
+			return max(time.Duration(seconds)*time.Second, 0), true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return max(date.Sub(now), 0), true
+		}
+	}
+	if reset := header.Get("X-RateLimit-Reset"); reset != "" {
+		if seconds, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			// Values past a billion seconds are unix times rather than delays
+			if seconds > 1e9 {
+				return max(time.Unix(seconds, 0).Sub(now), 0), true
+			}
+			return max(time.Duration(seconds)*time.Second, 0), true
+		}
+	}
+	return 0, false
+}
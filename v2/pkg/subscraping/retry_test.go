@@ -0,0 +1,148 @@
+package subscraping
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	response := func(status int, header ...string) *http.Response {
+		response := &http.Response{StatusCode: status, Header: http.Header{}}
+		for i := 0; i+1 < len(header); i += 2 {
+			response.Header.Set(header[i], header[i+1])
+		}
+		return response
+	}
+
+	delay, retry := policy.backoff(0, nil, errors.New("connection reset by peer"))
+	assert.True(t, retry)
+	assert.True(t, delay >= 500*time.Millisecond && delay <= time.Second, delay)
+
+	delay, retry = policy.backoff(2, response(http.StatusBadGateway), errors.New("unexpected status code 502"))
+	assert.True(t, retry)
+	assert.True(t, delay >= 2*time.Second && delay <= 4*time.Second, delay)
+
+	_, retry = policy.backoff(3, response(http.StatusBadGateway), errors.New("unexpected status code 502"))
+	assert.False(t, retry, "retries exhausted")
+
+	_, retry = policy.backoff(0, response(http.StatusUnauthorized), errors.New("unexpected status code 401"))
+	assert.False(t, retry, "client error")
+
+	delay, retry = policy.backoff(0, response(http.StatusTooManyRequests, "Retry-After", "7"), errors.New("unexpected status code 429"))
+	assert.True(t, retry)
+	assert.Equal(t, 7*time.Second, delay)
+
+	_, retry = policy.backoff(0, response(http.StatusTooManyRequests, "Retry-After", "3600"), errors.New("unexpected status code 429"))
+	assert.False(t, retry, "server delay longer than the maximum delay")
+
+	// The backoff is capped by the maximum delay
+	delay = RetryPolicy{MaxRetries: 40, BaseDelay: time.Second, MaxDelay: 10 * time.Second}.jitter(35)
+	assert.True(t, delay >= 5*time.Second && delay <= 10*time.Second, delay)
+
+	// Without a base delay the requests are retried right away
+	delay, retry = RetryPolicy{MaxRetries: 1, MaxDelay: time.Minute}.backoff(0, nil, errors.New("connection reset by peer"))
+	assert.True(t, retry)
+	assert.Zero(t, delay)
+}
+
+func TestServerDelay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	header := func(key, value string) http.Header {
+		header := http.Header{}
+		header.Set(key, value)
+		return header
+	}
+
+	tests := []struct {
+		header http.Header
+		delay  time.Duration
+		ok     bool
+	}{
+		{header("Retry-After", "30"), 30 * time.Second, true},
+		{header("Retry-After", "Wed, 01 May 2024 12:00:45 GMT"), 45 * time.Second, true},
+		{header("Retry-After", "Wed, 01 May 2024 11:00:00 GMT"), 0, true},
+		{header("X-RateLimit-Reset", "12"), 12 * time.Second, true},
+		{header("X-RateLimit-Reset", "1714564820"), 20 * time.Second, true},
+		{header("X-RateLimit-Reset", "soon"), 0, false},
+		{http.Header{}, 0, false},
+	}
+	for _, test := range tests {
+		delay, ok := serverDelay(test.header, now)
+		assert.Equal(t, test.ok, ok, test.header)
+		assert.Equal(t, test.delay, delay, test.header)
+	}
+}
+
+func TestHTTPRequestRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "query", string(body))
+		switch {
+		case r.URL.Path == "/unauthorized":
+			w.WriteHeader(http.StatusUnauthorized)
+		case attempts == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case attempts == 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.WithValue(context.Background(), CtxSourceArg, "test")
+	multiRateLimiter, err := ratelimit.NewMultiLimiter(ctx, &ratelimit.Options{Key: "test", IsUnlimited: true, MaxCount: math.MaxUint32, Duration: time.Millisecond})
+	require.Nil(t, err)
+	defer multiRateLimiter.Stop()
+
+	session := &Session{
+		Client:           server.Client(),
+		MultiRateLimiter: multiRateLimiter,
+		Statistics:       NewStatisticsCollector(),
+		RetryPolicy:      RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second},
+	}
+	resp, err := session.Post(ctx, server.URL, "", nil, strings.NewReader("query"))
+	require.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 2, session.Statistics.Statistics()["test"].Retries)
+
+	// The policy of the source replaces the default one
+	attempts = 0
+	session.RetryPolicies = map[string]RetryPolicy{"test": {}}
+	resp, err = session.Post(ctx, server.URL, "", nil, strings.NewReader("query"))
+	require.NotNil(t, err)
+	session.DiscardHTTPResponse(resp)
+	assert.Equal(t, 1, attempts)
+
+	// Client errors are not retried
+	attempts = 0
+	session.RetryPolicies = nil
+	resp, err = session.Post(ctx, server.URL+"/unauthorized", "", nil, strings.NewReader("query"))
+	require.NotNil(t, err)
+	session.DiscardHTTPResponse(resp)
+	assert.Equal(t, 1, attempts)
+
+	// Requests which could not be created are not retried
+	attempts = 0
+	_, err = session.Get(ctx, "://invalid", "", nil)
+	require.NotNil(t, err)
+	assert.Equal(t, 2, session.Statistics.Statistics()["test"].Retries)
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
@@ -19,28 +18,18 @@ type alienvaultResponse struct {
 }
 
 // Source is the passive scraping agent
-type Source struct {
-	timeTaken time.Duration
-	results   int
-	errors    int
-}
+type Source struct{}
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		resp, err := session.SimpleGet(ctx, fmt.Sprintf("https://otx.alienvault.com/api/v1/indicators/domain/%s/passive_dns", domain))
 		if err != nil && resp == nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			session.DiscardHTTPResponse(resp)
 			return
 		}
@@ -50,7 +39,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		err = json.NewDecoder(resp.Body).Decode(&response)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			resp.Body.Close()
 			return
 		}
@@ -65,7 +53,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 
 		for _, record := range response.PassiveDNS {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: record.Hostname}
-			s.results++
 		}
 	}()
 
@@ -92,11 +79,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(_ []string) {
 	// no key needed
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-	}
-}
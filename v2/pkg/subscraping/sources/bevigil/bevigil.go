@@ -4,7 +4,6 @@ package bevigil
 import (
 	"context"
 	"fmt"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -17,27 +16,18 @@ type Response struct {
 }
 
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
@@ -48,7 +38,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		})
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			session.DiscardHTTPResponse(resp)
 			return
 		}
@@ -58,7 +47,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		err = jsoniter.NewDecoder(resp.Body).Decode(&response)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			resp.Body.Close()
 			return
 		}
@@ -71,7 +59,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 
 		for _, subdomain := range subdomains {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-			s.results++
 		}
 
 	}()
@@ -97,12 +84,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -8,7 +8,6 @@ import (
 	"math"
 	"net/url"
 	"strconv"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -38,28 +37,19 @@ type subdomainsResponse struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
@@ -74,7 +64,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 			v1URLWithPageSize, err := addURLParam(fmt.Sprintf(baseAPIURLFmt, v1, domain), v1PageSizeParam, strconv.Itoa(maxV1PageSize))
 			if err != nil {
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
 				return
 			}
 			baseURL = v1URLWithPageSize.String()
@@ -84,7 +73,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 			results <- subscraping.Result{
 				Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("can't get API URL"),
 			}
-			s.errors++
 			return
 		}
 
@@ -97,14 +85,12 @@ func (s *Source) enumerate(ctx context.Context, session *subscraping.Session, ba
 	pageURL, err := addURLParam(baseURL, pageParam, strconv.Itoa(page))
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		return
 	}
 
 	resp, err := session.Get(ctx, pageURL.String(), "", authHeader)
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		session.DiscardHTTPResponse(resp)
 		return
 	}
@@ -113,7 +99,6 @@ func (s *Source) enumerate(ctx context.Context, session *subscraping.Session, ba
 	err = jsoniter.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		resp.Body.Close()
 		return
 	}
@@ -121,7 +106,6 @@ func (s *Source) enumerate(ctx context.Context, session *subscraping.Session, ba
 	// Check error messages
 	if response.Message != "" && response.Status != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: errors.New(response.Message)}
-		s.errors++
 		return
 	}
 
@@ -129,7 +113,6 @@ func (s *Source) enumerate(ctx context.Context, session *subscraping.Session, ba
 
 	for _, subdomain := range response.Subdomains {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-		s.results++
 	}
 
 	totalPages := int(math.Ceil(float64(response.Total) / float64(response.PageSize)))
@@ -160,15 +143,6 @@ func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
 
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
-
 func isV2(ctx context.Context, session *subscraping.Session, authHeader map[string]string) bool {
 	resp, err := session.Get(ctx, v2SubscriptionURL, "", authHeader)
 	if err != nil {
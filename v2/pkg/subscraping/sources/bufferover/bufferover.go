@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -23,28 +22,19 @@ type response struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
@@ -59,7 +49,6 @@ func (s *Source) getData(ctx context.Context, sourceURL string, apiKey string, s
 
 	if err != nil && resp == nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		session.DiscardHTTPResponse(resp)
 		return
 	}
@@ -68,7 +57,6 @@ func (s *Source) getData(ctx context.Context, sourceURL string, apiKey string, s
 	err = jsoniter.NewDecoder(resp.Body).Decode(&bufforesponse)
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		resp.Body.Close()
 		return
 	}
@@ -81,7 +69,6 @@ func (s *Source) getData(ctx context.Context, sourceURL string, apiKey string, s
 		results <- subscraping.Result{
 			Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("%s", strings.Join(metaErrors, ", ")),
 		}
-		s.errors++
 		return
 	}
 
@@ -97,7 +84,6 @@ func (s *Source) getData(ctx context.Context, sourceURL string, apiKey string, s
 	for _, subdomain := range subdomains {
 		for _, value := range session.Extractor.Extract(subdomain) {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: value}
-			s.results++
 		}
 	}
 }
@@ -122,12 +108,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
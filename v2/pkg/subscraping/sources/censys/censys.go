@@ -4,7 +4,6 @@ package censys
 import (
 	"context"
 	"strconv"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -48,6 +47,16 @@ type validityPeriod struct {
 	NotBefore string `json:"not_before"`
 }
 
+// metadata returns the fingerprint of the certificate. Its validity period tells
+// when the certificate can be used rather than when the name was observed.
+func (h hit) metadata() *subscraping.Metadata {
+	metadata := &subscraping.Metadata{}
+	if h.FingerprintSha256 != "" {
+		metadata.Fingerprints = []string{h.FingerprintSha256}
+	}
+	return metadata
+}
+
 type links struct {
 	Next string `json:"next"`
 	Prev string `json:"prev"`
@@ -55,11 +64,7 @@ type links struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []apiKey
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []apiKey
 }
 
 type apiKey struct {
@@ -70,36 +75,28 @@ type apiKey struct {
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey.token == "" || randomApiKey.secret == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
 		certSearchEndpoint := "https://search.censys.io/api/v2/certificates/search"
-		cursor := ""
-		currentPage := 1
-		for {
+		paginator := subscraping.Paginator{Style: subscraping.Cursor, PageLimits: subscraping.PageLimits{MaxPages: maxCensysPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
 			certSearchEndpointUrl, err := urlutil.Parse(certSearchEndpoint)
 			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				return
+				return subscraping.PageResponse{}, err
 			}
 
 			certSearchEndpointUrl.Params.Add("q", domain)
 			certSearchEndpointUrl.Params.Add("per_page", strconv.Itoa(maxPerPage))
-			if cursor != "" {
-				certSearchEndpointUrl.Params.Add("cursor", cursor)
+			if page.Cursor != "" {
+				certSearchEndpointUrl.Params.Add("cursor", page.Cursor)
 			}
 
 			resp, err := session.HTTPRequest(
@@ -111,38 +108,27 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 				nil,
 				subscraping.BasicAuth{Username: randomApiKey.token, Password: randomApiKey.secret},
 			)
-
 			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
 				session.DiscardHTTPResponse(resp)
-				return
+				return subscraping.PageResponse{}, err
 			}
+			defer resp.Body.Close()
 
 			var censysResponse response
-			err = jsoniter.NewDecoder(resp.Body).Decode(&censysResponse)
-			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				resp.Body.Close()
-				return
+			if err := jsoniter.NewDecoder(resp.Body).Decode(&censysResponse); err != nil {
+				return subscraping.PageResponse{}, err
 			}
 
-			resp.Body.Close()
-
 			for _, hit := range censysResponse.Result.Hits {
+				metadata := hit.metadata()
 				for _, name := range hit.Names {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: name}
-					s.results++
+					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: name, Metadata: metadata}
 				}
 			}
-
-			// Exit the censys enumeration if last page is reached
-			cursor = censysResponse.Result.Links.Next
-			if cursor == "" || currentPage >= maxCensysPages {
-				break
-			}
-			currentPage++
+			return subscraping.PageResponse{Results: len(censysResponse.Result.Hits), Next: censysResponse.Result.Links.Next}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 	}()
 
@@ -171,12 +157,3 @@ func (s *Source) AddApiKeys(keys []string) {
 		return apiKey{k, v}
 	})
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
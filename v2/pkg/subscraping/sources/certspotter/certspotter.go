@@ -4,7 +4,6 @@ package certspotter
 import (
 	"context"
 	"fmt"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -18,97 +17,54 @@ type certspotterObject struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
 		headers := map[string]string{"Authorization": "Bearer " + randomApiKey}
-		cookies := ""
-
-		resp, err := session.Get(ctx, fmt.Sprintf("https://api.certspotter.com/v1/issuances?domain=%s&include_subdomains=true&expand=dns_names", domain), cookies, headers)
-		if err != nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
-			session.DiscardHTTPResponse(resp)
-			return
-		}
-
-		var response []certspotterObject
-		err = jsoniter.NewDecoder(resp.Body).Decode(&response)
-		if err != nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
-			resp.Body.Close()
-			return
-		}
-		resp.Body.Close()
-
-		for _, cert := range response {
-			for _, subdomain := range cert.DNSNames {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-				s.results++
+		paginator := subscraping.Paginator{Style: subscraping.Cursor, PageLimits: subscraping.PageLimits{MaxPages: subscraping.UnlimitedPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
+			reqURL := fmt.Sprintf("https://api.certspotter.com/v1/issuances?domain=%s&include_subdomains=true&expand=dns_names", domain)
+			if page.Cursor != "" {
+				reqURL += "&after=" + page.Cursor
 			}
-		}
-
-		// if the number of responses is zero, close the channel and return.
-		if len(response) == 0 {
-			return
-		}
-
-		id := response[len(response)-1].ID
-		for {
-			reqURL := fmt.Sprintf("https://api.certspotter.com/v1/issuances?domain=%s&include_subdomains=true&expand=dns_names&after=%s", domain, id)
-
-			resp, err := session.Get(ctx, reqURL, cookies, headers)
+			resp, err := session.Get(ctx, reqURL, "", headers)
 			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				return
+				session.DiscardHTTPResponse(resp)
+				return subscraping.PageResponse{}, err
 			}
+			defer resp.Body.Close()
 
 			var response []certspotterObject
-			err = jsoniter.NewDecoder(resp.Body).Decode(&response)
-			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				resp.Body.Close()
-				return
+			if err := jsoniter.NewDecoder(resp.Body).Decode(&response); err != nil {
+				return subscraping.PageResponse{}, err
 			}
-			resp.Body.Close()
-
+			// if the number of responses is zero, there is no more page
 			if len(response) == 0 {
-				break
+				return subscraping.PageResponse{}, nil
 			}
 
 			for _, cert := range response {
 				for _, subdomain := range cert.DNSNames {
 					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-					s.results++
 				}
 			}
-
-			id = response[len(response)-1].ID
+			return subscraping.PageResponse{Results: len(response), Next: response[len(response)-1].ID}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 	}()
 
@@ -135,12 +91,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
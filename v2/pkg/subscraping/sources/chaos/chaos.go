@@ -4,7 +4,6 @@ package chaos
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 	"github.com/projectdiscovery/chaos-client/pkg/chaos"
@@ -12,28 +11,19 @@ import (
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
-func (s *Source) Run(_ context.Context, domain string, _ *subscraping.Session) <-chan subscraping.Result {
+func (s *Source) Run(_ context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
@@ -43,13 +33,11 @@ func (s *Source) Run(_ context.Context, domain string, _ *subscraping.Session) <
 		}) {
 			if result.Error != nil {
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: result.Error}
-				s.errors++
 				break
 			}
 			results <- subscraping.Result{
 				Source: s.Name(), Type: subscraping.Subdomain, Value: fmt.Sprintf("%s.%s", result.Subdomain, domain),
 			}
-			s.results++
 		}
 	}()
 
@@ -76,12 +64,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -24,23 +23,14 @@ type subdomain struct {
 }
 
 // Source is the passive scraping agent
-type Source struct {
-	timeTaken time.Duration
-	errors    int
-	results   int
-}
+type Source struct{}
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		count := s.getSubdomainsFromSQL(ctx, domain, session, results)
 		if count > 0 {
@@ -56,7 +46,6 @@ func (s *Source) getSubdomainsFromSQL(ctx context.Context, domain string, sessio
 	db, err := sql.Open("postgres", "host=crt.sh user=guest dbname=certwatch sslmode=disable binary_parameters=yes")
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		return 0
 	}
 
@@ -98,12 +87,10 @@ func (s *Source) getSubdomainsFromSQL(ctx context.Context, domain string, sessio
 	rows, err := db.QueryContext(ctx, query, domain)
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		return 0
 	}
 	if err := rows.Err(); err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		return 0
 	}
 
@@ -114,7 +101,6 @@ func (s *Source) getSubdomainsFromSQL(ctx context.Context, domain string, sessio
 		err := rows.Scan(&data)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			return count
 		}
 
@@ -123,7 +109,6 @@ func (s *Source) getSubdomainsFromSQL(ctx context.Context, domain string, sessio
 			for _, value := range session.Extractor.Extract(subdomain) {
 				if value != "" {
 					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: value}
-					s.results++
 				}
 			}
 		}
@@ -135,7 +120,6 @@ func (s *Source) getSubdomainsFromHTTP(ctx context.Context, domain string, sessi
 	resp, err := session.SimpleGet(ctx, fmt.Sprintf("https://crt.sh/?q=%%25.%s&output=json", domain))
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		session.DiscardHTTPResponse(resp)
 		return false
 	}
@@ -144,7 +128,6 @@ func (s *Source) getSubdomainsFromHTTP(ctx context.Context, domain string, sessi
 	err = jsoniter.NewDecoder(resp.Body).Decode(&subdomains)
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		resp.Body.Close()
 		return false
 	}
@@ -156,7 +139,6 @@ func (s *Source) getSubdomainsFromHTTP(ctx context.Context, domain string, sessi
 			for _, value := range session.Extractor.Extract(sub) {
 				if value != "" {
 					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: value}
-					s.results++
 				}
 			}
 		}
@@ -185,11 +167,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(_ []string) {
 	// no key needed
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-	}
-}
@@ -0,0 +1,475 @@
+// Package custom implements the sources declared in a YAML file, which
+// query HTTP-JSON APIs without needing a Go implementation, e.g.
+//
+//	# custom-sources.yaml
+//	- name: internaldns
+//	  url: https://dns.example.net/api/v1/subdomains?domain={domain}&page={page}
+//	  auth:
+//	    type: header
+//	    name: X-API-Key
+//	  extract:
+//	    jsonpath: ['$.data[*].hostname']
+//	  pagination:
+//	    type: page
+//	    has-more: $.meta.has_more
+//	  rate-limit: 5/s
+//
+// A source can also run an executable instead, which prints its results as
+// NDJSON lines, see PluginSource:
+//
+//	# custom-sources.yaml
+//	- name: internal-scanner
+//	  command: /opt/recon/scan.py
+//	  args: ['--domain', '{domain}']
+//	  needs-key: true
+//
+// The API keys of the sources are read from the provider config, under their name.
+package custom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// defaultMaxPages is the number of pages requested when the definition sets no limit
+const defaultMaxPages = 100
+
+// Authentication styles of the API keys
+const (
+	AuthNone   = "none"
+	AuthHeader = "header"
+	AuthQuery  = "query"
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+)
+
+// Pagination strategies
+const (
+	PaginationPage   = "page"
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+)
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Definition describes a source querying an HTTP-JSON API. The URL, the
+// headers and the body are templates where {domain}, {key} and {page} are
+// replaced with the domain enumerated, an API key from the provider config
+// and the page number, offset or cursor of the request. A definition with
+// a command describes a plugin source instead, which only uses the name,
+// command, args, needs-key, default and recursive fields.
+type Definition struct {
+	Name       string            `yaml:"name"`
+	URL        string            `yaml:"url"`
+	Method     string            `yaml:"method"`
+	Headers    map[string]string `yaml:"headers"`
+	Body       string            `yaml:"body"`
+	Auth       Auth              `yaml:"auth"`
+	Extract    Extract           `yaml:"extract"`
+	Pagination Pagination        `yaml:"pagination"`
+	// RateLimit is the maximum number of requests in the -rls format, e.g. 10/s
+	RateLimit string `yaml:"rate-limit"`
+	Default   bool   `yaml:"default"`
+	Recursive bool   `yaml:"recursive"`
+	// Command is the executable of a plugin source, run with the args where
+	// {domain} is replaced with the domain enumerated
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	// NeedsKey specifies whether the source needs an API key from the provider config
+	NeedsKey bool `yaml:"needs-key"`
+
+	regex      *regexp.Regexp
+	jsonPaths  []*jsonPath
+	cursorPath *jsonPath
+	hasMore    *jsonPath
+}
+
+// Auth describes how the API key is sent, in addition to the {key} placeholders
+type Auth struct {
+	// Type is one of none, header, query, bearer or basic. Basic
+	// authentication takes keys in the username:password format.
+	Type string `yaml:"type"`
+	// Name is the header or the query parameter holding the key
+	Name string `yaml:"name"`
+}
+
+// Extract describes how the hostnames are extracted from a response. Without
+// JSONPath nor regex, the subdomains of the domain are searched in the whole body.
+type Extract struct {
+	JSONPath []string `yaml:"jsonpath"`
+	// Regex is matched against the values selected by the JSONPath expressions,
+	// or against the whole body. Its first group is the hostname, if it has one.
+	Regex string `yaml:"regex"`
+}
+
+// Pagination describes how the pages of the results are requested. The
+// requests stop once a page has no hostname, once the has-more field is
+// false or empty, once the cursor is empty or after max-pages pages.
+type Pagination struct {
+	// Type is one of page, offset or cursor, empty to request a single page
+	Type string `yaml:"type"`
+	// Start is the first page number or offset
+	Start int `yaml:"start"`
+	// Size is the number of results per page the offset is increased by
+	Size int `yaml:"size"`
+	// Cursor is the JSONPath of the cursor of the next page
+	Cursor string `yaml:"cursor"`
+	// HasMore is the JSONPath of a field telling whether more pages follow
+	HasMore  string `yaml:"has-more"`
+	MaxPages int    `yaml:"max-pages"`
+}
+
+// Load reads and validates the source definitions of the YAML file
+func Load(path string) ([]*Definition, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var definitions []*Definition
+	if err := yaml.Unmarshal(content, &definitions); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(definitions))
+	for i, definition := range definitions {
+		if err := definition.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid source %d: %s", i+1, err)
+		}
+		if _, ok := seen[definition.Name]; ok {
+			return nil, fmt.Errorf("source %s is defined twice", definition.Name)
+		}
+		seen[definition.Name] = struct{}{}
+	}
+	return definitions, nil
+}
+
+// Validate checks the definition, filling in the defaults and compiling its expressions
+func (d *Definition) Validate() error {
+	d.Name = strings.ToLower(d.Name)
+	if !nameRegex.MatchString(d.Name) {
+		return fmt.Errorf("invalid name %q", d.Name)
+	}
+	if d.Command != "" {
+		if d.URL != "" {
+			return fmt.Errorf("%s: a source has either a url or a command", d.Name)
+		}
+		return nil
+	}
+	if !strings.HasPrefix(d.URL, "http://") && !strings.HasPrefix(d.URL, "https://") {
+		return fmt.Errorf("%s: url must start with http:// or https://", d.Name)
+	}
+	d.Method = strings.ToUpper(d.Method)
+	if d.Method == "" {
+		d.Method = http.MethodGet
+	}
+
+	d.Auth.Type = strings.ToLower(d.Auth.Type)
+	switch d.Auth.Type {
+	case "":
+		d.Auth.Type = AuthNone
+	case AuthNone, AuthBearer, AuthBasic:
+	case AuthHeader:
+		if d.Auth.Name == "" {
+			d.Auth.Name = "X-API-Key"
+		}
+	case AuthQuery:
+		if d.Auth.Name == "" {
+			d.Auth.Name = "key"
+		}
+	default:
+		return fmt.Errorf("%s: unknown auth type %s", d.Name, d.Auth.Type)
+	}
+
+	var err error
+	d.jsonPaths = nil
+	for _, expression := range d.Extract.JSONPath {
+		path, err := compileJSONPath(expression)
+		if err != nil {
+			return fmt.Errorf("%s: %s", d.Name, err)
+		}
+		d.jsonPaths = append(d.jsonPaths, path)
+	}
+	d.regex = nil
+	if d.Extract.Regex != "" {
+		if d.regex, err = regexp.Compile(d.Extract.Regex); err != nil {
+			return fmt.Errorf("%s: invalid regex: %s", d.Name, err)
+		}
+	}
+
+	pagination := &d.Pagination
+	pagination.Type = strings.ToLower(pagination.Type)
+	switch pagination.Type {
+	case "", PaginationPage:
+	case PaginationOffset:
+		if pagination.Size <= 0 {
+			return fmt.Errorf("%s: offset pagination needs a size", d.Name)
+		}
+	case PaginationCursor:
+		if pagination.Cursor == "" {
+			return fmt.Errorf("%s: cursor pagination needs a cursor", d.Name)
+		}
+	default:
+		return fmt.Errorf("%s: unknown pagination type %s", d.Name, pagination.Type)
+	}
+	if pagination.Type == PaginationPage && pagination.Start == 0 {
+		pagination.Start = 1
+	}
+	if pagination.MaxPages <= 0 {
+		pagination.MaxPages = defaultMaxPages
+	}
+	d.cursorPath, d.hasMore = nil, nil
+	if pagination.Cursor != "" {
+		if d.cursorPath, err = compileJSONPath(pagination.Cursor); err != nil {
+			return fmt.Errorf("%s: %s", d.Name, err)
+		}
+	}
+	if pagination.HasMore != "" {
+		if d.hasMore, err = compileJSONPath(pagination.HasMore); err != nil {
+			return fmt.Errorf("%s: %s", d.Name, err)
+		}
+	}
+	return nil
+}
+
+// needsKey returns true if the requests of the source carry an API key
+func (d *Definition) needsKey() bool {
+	if d.NeedsKey {
+		return true
+	}
+	if d.Command != "" {
+		return false
+	}
+	if d.Auth.Type != AuthNone || strings.Contains(d.URL, "{key}") || strings.Contains(d.Body, "{key}") {
+		return true
+	}
+	for _, value := range d.Headers {
+		if strings.Contains(value, "{key}") {
+			return true
+		}
+	}
+	return false
+}
+
+// paginationStyles maps the pagination types to the styles of the session paginator
+var paginationStyles = map[string]subscraping.PaginationStyle{
+	PaginationPage:   subscraping.PageNumber,
+	PaginationOffset: subscraping.Offset,
+	PaginationCursor: subscraping.Cursor,
+}
+
+// Source is the passive scraping agent of an HTTP-JSON source definition
+type Source struct {
+	definition *Definition
+	apiKeys    []string
+}
+
+// New creates the HTTP or plugin source of a validated definition
+func New(definition *Definition) subscraping.Source {
+	if definition.Command != "" {
+		return &PluginSource{definition: definition}
+	}
+	return &Source{definition: definition}
+}
+
+// Run function returns all subdomains found with the service
+func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+
+	go func() {
+		defer close(results)
+
+		var apiKey string
+		if s.NeedsKey() {
+			apiKey = subscraping.PickRandom(s.apiKeys, s.Name())
+			if apiKey == "" {
+				session.Statistics.SetSkipped(s.Name())
+				return
+			}
+		}
+
+		var responses []string
+		pagination := s.definition.Pagination
+		fetch := func(page subscraping.Page) (subscraping.PageResponse, error) {
+			var current string
+			switch pagination.Type {
+			case PaginationPage:
+				current = strconv.Itoa(page.Number)
+			case PaginationOffset:
+				current = strconv.Itoa(page.Offset)
+			case PaginationCursor:
+				current = page.Cursor
+			}
+			body, err := s.request(ctx, session, domain, apiKey, current)
+			if err != nil {
+				return subscraping.PageResponse{}, err
+			}
+			responses = append(responses, string(body))
+
+			hostnames := s.extract(body, session)
+			for _, hostname := range hostnames {
+				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: hostname}
+			}
+
+			// Without a has-more field, the pages are requested until one has no hostname
+			response := subscraping.PageResponse{Results: len(hostnames), More: true}
+			if s.definition.hasMore != nil {
+				response.More = s.definition.hasMore.truthy(body)
+			}
+			if pagination.Type == PaginationCursor && response.More {
+				if cursors := s.definition.cursorPath.strings(body); len(cursors) > 0 && cursors[0] != current {
+					response.Next = cursors[0]
+				}
+			}
+			return response, nil
+		}
+
+		var err error
+		if pagination.Type == "" {
+			_, err = fetch(subscraping.Page{})
+		} else {
+			err = session.Paginate(ctx, subscraping.Paginator{
+				Style:      paginationStyles[pagination.Type],
+				Start:      pagination.Start,
+				Size:       pagination.Size,
+				PageLimits: subscraping.PageLimits{MaxPages: pagination.MaxPages},
+			}, fetch)
+		}
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
+		}
+		if session.RespFileDirectory != "" {
+			subscraping.WriteResponseData(responses, s.Name(), session.RespFileDirectory)
+		}
+	}()
+
+	return results
+}
+
+// request requests a page of results and returns its body
+func (s *Source) request(ctx context.Context, session *subscraping.Session, domain, apiKey, page string) ([]byte, error) {
+	definition := s.definition
+	values := map[string]string{"{domain}": domain, "{key}": apiKey, "{page}": page}
+
+	requestURL := expand(definition.URL, values, url.QueryEscape)
+	if definition.Auth.Type == AuthQuery {
+		parsed, err := url.Parse(requestURL)
+		if err != nil {
+			return nil, err
+		}
+		query := parsed.Query()
+		query.Set(definition.Auth.Name, apiKey)
+		parsed.RawQuery = query.Encode()
+		requestURL = parsed.String()
+	}
+
+	headers := map[string]string{"Accept": "application/json"}
+	for name, value := range definition.Headers {
+		headers[name] = expand(value, values, nil)
+	}
+	var basicAuth subscraping.BasicAuth
+	switch definition.Auth.Type {
+	case AuthHeader:
+		headers[definition.Auth.Name] = apiKey
+	case AuthBearer:
+		headers["Authorization"] = "Bearer " + apiKey
+	case AuthBasic:
+		username, password, ok := strings.Cut(apiKey, ":")
+		if !ok {
+			return nil, errors.New("basic auth keys must be in the username:password format")
+		}
+		basicAuth = subscraping.BasicAuth{Username: username, Password: password}
+	}
+
+	var body io.Reader
+	if definition.Body != "" {
+		body = strings.NewReader(expand(definition.Body, values, nil))
+	}
+
+	resp, err := session.HTTPRequest(ctx, definition.Method, requestURL, "", headers, body, basicAuth)
+	if err != nil {
+		session.DiscardHTTPResponse(resp)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// extract returns the hostnames found in the body of a response
+func (s *Source) extract(body []byte, session *subscraping.Session) []string {
+	definition := s.definition
+	if len(definition.jsonPaths) == 0 {
+		if definition.regex == nil {
+			return session.Extractor.Extract(string(body))
+		}
+		return match(definition.regex, string(body))
+	}
+
+	var hostnames []string
+	for _, path := range definition.jsonPaths {
+		for _, value := range path.strings(body) {
+			if definition.regex == nil {
+				hostnames = append(hostnames, value)
+				continue
+			}
+			hostnames = append(hostnames, match(definition.regex, value)...)
+		}
+	}
+	return hostnames
+}
+
+// match returns the first group of every match of the regex, or the whole match if it has no group
+func match(regex *regexp.Regexp, text string) []string {
+	var hostnames []string
+	for _, submatches := range regex.FindAllStringSubmatch(text, -1) {
+		hostname := submatches[0]
+		if len(submatches) > 1 {
+			hostname = submatches[1]
+		}
+		hostnames = append(hostnames, hostname)
+	}
+	return hostnames
+}
+
+// expand replaces the placeholders of the template, escaping their values if an escape function is given
+func expand(template string, values map[string]string, escape func(string) string) string {
+	for placeholder, value := range values {
+		if escape != nil {
+			value = escape(value)
+		}
+		template = strings.ReplaceAll(template, placeholder, value)
+	}
+	return template
+}
+
+// Name returns the name of the source
+func (s *Source) Name() string {
+	return s.definition.Name
+}
+
+func (s *Source) IsDefault() bool {
+	return s.definition.Default
+}
+
+func (s *Source) HasRecursiveSupport() bool {
+	return s.definition.Recursive
+}
+
+func (s *Source) NeedsKey() bool {
+	return s.definition.needsKey()
+}
+
+func (s *Source) AddApiKeys(keys []string) {
+	s.apiKeys = keys
+}
@@ -0,0 +1,133 @@
+package custom
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+func TestCompileJSONPath(t *testing.T) {
+	tests := map[string]string{
+		"$.data[*].hostname":      "data.#.hostname",
+		"$['results'][0].name":    "results.0.name",
+		"$.meta.next.cursor":      "meta.next.cursor",
+		"$.items.*.host":          "items.#.host",
+		`$["dotted.key"]`:         `dotted\.key`,
+		"$.subdomains[*]":         "subdomains.#",
+		"$.data[*].names[*].host": "data.#.names.#.host",
+	}
+	for expression, expected := range tests {
+		path, err := compileJSONPath(expression)
+		require.Nil(t, err, expression)
+		assert.Equal(t, expected, path.path, expression)
+	}
+
+	for _, expression := range []string{"data.hostname", "$..hostname", "$.data[?(@.a)]", "$"} {
+		_, err := compileJSONPath(expression)
+		assert.NotNil(t, err, expression)
+	}
+
+	path, err := compileJSONPath("$.data[*].names[*].host")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, path.strings([]byte(`{"data":[{"names":[{"host":"a"},{"host":"b"}]},{"names":[{"host":"c"}]}]}`)))
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom-sources.yaml")
+	require.Nil(t, os.WriteFile(path, []byte(`
+- name: Internal
+  url: https://api.example.com/{domain}?page={page}
+  auth:
+    type: header
+  extract:
+    jsonpath: ['$.data[*].hostname']
+  pagination:
+    type: page
+  rate-limit: 10/s
+`), 0644))
+	definitions, err := Load(path)
+	require.Nil(t, err)
+	require.Len(t, definitions, 1)
+
+	source := New(definitions[0])
+	assert.Equal(t, "internal", source.Name())
+	assert.True(t, source.NeedsKey())
+	assert.Equal(t, "X-API-Key", definitions[0].Auth.Name)
+	assert.Equal(t, 1, definitions[0].Pagination.Start)
+	assert.Equal(t, defaultMaxPages, definitions[0].Pagination.MaxPages)
+
+	invalid := []string{
+		"- name: missing-url\n",
+		"- name: bad\n  url: https://example.com\n  auth: {type: cookie}\n",
+		"- name: bad\n  url: https://example.com\n  pagination: {type: offset}\n",
+		"- name: bad\n  url: https://example.com\n  pagination: {type: cursor}\n",
+		"- name: bad\n  url: https://example.com\n  extract: {regex: '('}\n",
+		"- name: twice\n  url: https://example.com\n- name: twice\n  url: https://example.com\n",
+	}
+	for _, content := range invalid {
+		require.Nil(t, os.WriteFile(path, []byte(content), 0644))
+		_, err := Load(path)
+		assert.NotNil(t, err, content)
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "", http.StatusUnauthorized)
+			return
+		}
+		switch req.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte(`{"items":[{"host":"a.example.com"},{"host":"b.example.com"}],"next":"c2"}`))
+		case "c2":
+			_, _ = w.Write([]byte(`{"items":[{"host":"c.example.com"}],"next":""}`))
+		default:
+			http.Error(w, "", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	definition := &Definition{
+		Name:       "cursored",
+		URL:        server.URL + "/search?q={domain}&cursor={page}",
+		Auth:       Auth{Type: AuthBearer},
+		Extract:    Extract{JSONPath: []string{"$.items[*].host"}},
+		Pagination: Pagination{Type: PaginationCursor, Cursor: "$.next"},
+	}
+	require.Nil(t, definition.Validate())
+	source := New(definition).(*Source)
+	source.AddApiKeys([]string{"secret"})
+
+	ctx := context.WithValue(context.Background(), subscraping.CtxSourceArg, source.Name())
+	multiRateLimiter, err := ratelimit.NewMultiLimiter(ctx, &ratelimit.Options{Key: source.Name(), IsUnlimited: true, MaxCount: math.MaxUint32, Duration: time.Millisecond})
+	require.Nil(t, err)
+	session, err := subscraping.NewSession("example.com", "", multiRateLimiter, 5, "")
+	require.Nil(t, err)
+
+	var hostnames []string
+	for result := range source.Run(ctx, "example.com", session) {
+		require.Equal(t, subscraping.Subdomain, result.Type, result.Error)
+		hostnames = append(hostnames, result.Value)
+	}
+	sort.Strings(hostnames)
+	assert.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com"}, hostnames)
+}
+
+func TestMatch(t *testing.T) {
+	definition := &Definition{Name: "regex", URL: "https://example.com", Extract: Extract{Regex: `host=([a-z0-9.-]+)`}}
+	require.Nil(t, definition.Validate())
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, New(definition).(*Source).extract([]byte("host=a.example.com&host=b.example.com"), nil))
+}
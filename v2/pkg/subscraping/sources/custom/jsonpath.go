@@ -0,0 +1,114 @@
+package custom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// jsonPath is a JSONPath expression compiled to a gjson path. The
+// supported subset covers dotted and bracketed member names, array
+// indexes and the [*] wildcard, e.g. $.data[*].hostname.
+type jsonPath struct {
+	expression string
+	path       string
+}
+
+// compileJSONPath converts the JSONPath expression to a gjson path
+func compileJSONPath(expression string) (*jsonPath, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expression), "$")
+	if !ok {
+		return nil, fmt.Errorf("jsonpath %s must start with $", expression)
+	}
+
+	var parts []string
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return nil, fmt.Errorf("recursive descent is not supported in jsonpath %s", expression)
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("empty member name in jsonpath %s", expression)
+			}
+			if name == "*" {
+				parts = append(parts, "#")
+			} else {
+				parts = append(parts, escapeGJSON(name))
+			}
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in jsonpath %s", expression)
+			}
+			selector := rest[1:end]
+			switch {
+			case selector == "*":
+				parts = append(parts, "#")
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				parts = append(parts, escapeGJSON(selector[1:len(selector)-1]))
+			default:
+				if _, err := strconv.Atoi(selector); err != nil {
+					return nil, fmt.Errorf("unsupported selector [%s] in jsonpath %s", selector, expression)
+				}
+				parts = append(parts, selector)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in jsonpath %s", rest[0], expression)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("jsonpath %s selects the whole document", expression)
+	}
+	return &jsonPath{expression: expression, path: strings.Join(parts, ".")}, nil
+}
+
+// escapeGJSON escapes the characters of a member name which are special in gjson paths
+func escapeGJSON(name string) string {
+	var builder strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`.*?|#@\!=<>%`, r) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// strings returns the scalar values the path selects in the document, flattening arrays
+func (p *jsonPath) strings(document []byte) []string {
+	var values []string
+	var collect func(result gjson.Result)
+	collect = func(result gjson.Result) {
+		switch {
+		case result.IsArray():
+			for _, item := range result.Array() {
+				collect(item)
+			}
+		case result.Type == gjson.String || result.Type == gjson.Number:
+			values = append(values, result.String())
+		}
+	}
+	collect(gjson.GetBytes(document, p.path))
+	return values
+}
+
+// truthy returns true if the path selects a value which is not false, null, zero or empty
+func (p *jsonPath) truthy(document []byte) bool {
+	result := gjson.GetBytes(document, p.path)
+	switch {
+	case result.IsArray():
+		return len(result.Array()) > 0
+	case result.IsObject():
+		return true
+	}
+	return result.Bool() || (result.Type == gjson.String && result.String() != "" && result.String() != "false" && result.String() != "0")
+}
@@ -0,0 +1,199 @@
+package custom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+const (
+	// maxPluginLine is the maximum size of a line printed by a plugin
+	maxPluginLine = 1 << 20
+	// pluginWaitDelay is how long the output of a killed plugin is waited for
+	pluginWaitDelay = 2 * time.Second
+)
+
+// Environment variables passed to the plugins
+const (
+	envDomain  = "SUBFINDER_DOMAIN"
+	envAPIKey  = "SUBFINDER_API_KEY"
+	envAPIKeys = "SUBFINDER_API_KEYS"
+)
+
+// pluginLine is a line printed by a plugin on its standard output
+type pluginLine struct {
+	// Type is either subdomain or error
+	Type     string          `json:"type"`
+	Value    string          `json:"value"`
+	Error    string          `json:"error"`
+	Metadata *pluginMetadata `json:"metadata"`
+}
+
+type pluginMetadata struct {
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	IPs          []string  `json:"ips"`
+	Ports        []int     `json:"ports"`
+	Fingerprints []string  `json:"fingerprints"`
+}
+
+// PluginSource is the passive scraping agent of a plugin source. The
+// executable is run with the domain, in its args or as its last argument,
+// and an API key from the provider config in the SUBFINDER_API_KEY
+// environment variable. It prints one JSON object per line on its standard
+// output, e.g. {"type":"subdomain","value":"www.example.com"} or
+// {"type":"error","error":"quota exceeded"}. The process is killed once the
+// enumeration is cancelled or times out.
+type PluginSource struct {
+	definition *Definition
+	apiKeys    []string
+}
+
+// Run function returns all subdomains found with the service
+func (s *PluginSource) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
+	results := make(chan subscraping.Result)
+
+	go func() {
+		defer close(results)
+
+		var apiKey string
+		if s.NeedsKey() {
+			apiKey = subscraping.PickRandom(s.apiKeys, s.Name())
+			if apiKey == "" {
+				session.Statistics.SetSkipped(s.Name())
+				return
+			}
+		}
+
+		cmd := exec.CommandContext(ctx, s.definition.Command, s.args(domain)...)
+		cmd.Env = append(os.Environ(),
+			envDomain+"="+domain,
+			envAPIKey+"="+apiKey,
+			envAPIKeys+"="+strings.Join(s.apiKeys, ","),
+		)
+		setProcessGroup(cmd)
+		cmd.WaitDelay = pluginWaitDelay
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
+			return
+		}
+
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxPluginLine)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			results <- s.result(line)
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("could not read output: %s", err)}
+		}
+
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				err = fmt.Errorf("%s: %s", err, lastLine(message))
+			}
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
+		}
+		if stderr.Len() > 0 {
+			gologger.Debug().Msgf("Output of plugin %s on standard error:\n%s", s.Name(), stderr.String())
+		}
+	}()
+
+	return results
+}
+
+// args returns the arguments of the executable for the domain
+func (s *PluginSource) args(domain string) []string {
+	args := make([]string, 0, len(s.definition.Args)+1)
+	hasDomain := false
+	for _, arg := range s.definition.Args {
+		if strings.Contains(arg, "{domain}") {
+			hasDomain = true
+		}
+		args = append(args, strings.ReplaceAll(arg, "{domain}", domain))
+	}
+	if !hasDomain {
+		args = append(args, domain)
+	}
+	return args
+}
+
+// result converts a line printed by the plugin to a result
+func (s *PluginSource) result(line []byte) subscraping.Result {
+	var parsed pluginLine
+	if err := jsoniter.Unmarshal(line, &parsed); err != nil {
+		return subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("invalid line %q: %s", line, err)}
+	}
+
+	switch parsed.Type {
+	case "subdomain", "":
+		if parsed.Value == "" {
+			return subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("line without value %q", line)}
+		}
+		result := subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: parsed.Value}
+		if metadata := parsed.Metadata; metadata != nil {
+			result.Metadata = &subscraping.Metadata{
+				FirstSeen:    metadata.FirstSeen,
+				LastSeen:     metadata.LastSeen,
+				IPs:          metadata.IPs,
+				Ports:        metadata.Ports,
+				Fingerprints: metadata.Fingerprints,
+			}
+		}
+		return result
+	case "error":
+		message := parsed.Error
+		if message == "" {
+			message = parsed.Value
+		}
+		return subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: errors.New(message)}
+	}
+	return subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("unknown line type %s", parsed.Type)}
+}
+
+// lastLine returns the last line of the text
+func lastLine(text string) string {
+	return text[strings.LastIndexByte(text, '\n')+1:]
+}
+
+// Name returns the name of the source
+func (s *PluginSource) Name() string {
+	return s.definition.Name
+}
+
+func (s *PluginSource) IsDefault() bool {
+	return s.definition.Default
+}
+
+func (s *PluginSource) HasRecursiveSupport() bool {
+	return s.definition.Recursive
+}
+
+func (s *PluginSource) NeedsKey() bool {
+	return s.definition.needsKey()
+}
+
+func (s *PluginSource) AddApiKeys(keys []string) {
+	s.apiKeys = keys
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package custom
+
+import "os/exec"
+
+// setProcessGroup leaves the plugin as it is on the systems without process
+// groups, where only the plugin itself is killed once the context is done
+func setProcessGroup(_ *exec.Cmd) {}
@@ -0,0 +1,101 @@
+package custom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
+)
+
+// writePlugin writes an executable shell script plugin
+func writePlugin(t *testing.T, script string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	require.Nil(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+	return path
+}
+
+func TestPluginSource(t *testing.T) {
+	definition := &Definition{
+		Name: "script",
+		Command: writePlugin(t, `echo '{"type":"subdomain","value":"a.'"$2"'","metadata":{"ips":["192.0.2.1"],"ports":[443]}}'
+echo
+echo '{"type":"error","error":"quota of '"$SUBFINDER_API_KEY"' exceeded"}'
+echo 'not json'
+echo "$SUBFINDER_DOMAIN" >&2
+exit 3
+`),
+		Args:     []string{"--domain", "{domain}"},
+		NeedsKey: true,
+	}
+	require.Nil(t, definition.Validate())
+	source := New(definition)
+	source.AddApiKeys([]string{"secret"})
+
+	session := &subscraping.Session{Statistics: subscraping.NewStatisticsCollector()}
+	var results []subscraping.Result
+	for result := range source.Run(context.Background(), "example.com", session) {
+		results = append(results, result)
+	}
+
+	require.Len(t, results, 4)
+	assert.Equal(t, subscraping.Result{
+		Source:   "script",
+		Type:     subscraping.Subdomain,
+		Value:    "a.example.com",
+		Metadata: &subscraping.Metadata{IPs: []string{"192.0.2.1"}, Ports: []int{443}},
+	}, results[0])
+	assert.EqualError(t, results[1].Error, "quota of secret exceeded")
+	assert.Equal(t, subscraping.Error, results[2].Type)
+	assert.EqualError(t, results[3].Error, "exit status 3: example.com")
+}
+
+func TestPluginSourceSkippedWithoutKey(t *testing.T) {
+	definition := &Definition{Name: "script", Command: writePlugin(t, "exit 1\n"), NeedsKey: true}
+	require.Nil(t, definition.Validate())
+
+	session := &subscraping.Session{Statistics: subscraping.NewStatisticsCollector()}
+	for range New(definition).Run(context.Background(), "example.com", session) {
+		t.Fatal("unexpected result")
+	}
+	assert.True(t, session.Statistics.Statistics()["script"].Skipped)
+}
+
+func TestPluginSourceCancelled(t *testing.T) {
+	definition := &Definition{Name: "script", Command: writePlugin(t, "echo '{\"value\":\"a.example.com\"}'\nsleep 30\n")}
+	require.Nil(t, definition.Validate())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	startTime := time.Now()
+	var values []string
+	for result := range New(definition).Run(ctx, "example.com", &subscraping.Session{}) {
+		values = append(values, result.Value)
+	}
+
+	assert.Equal(t, []string{"a.example.com"}, values)
+	assert.Less(t, time.Since(startTime), 10*time.Second)
+}
+
+func TestPluginSourceCancelledKillsChildren(t *testing.T) {
+	// The child holds the standard output of the plugin until it is killed as well
+	definition := &Definition{Name: "script", Command: writePlugin(t, "sleep 30 &\nwait\n")}
+	require.Nil(t, definition.Validate())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	startTime := time.Now()
+	for range New(definition).Run(ctx, "example.com", &subscraping.Session{}) {
+	}
+
+	assert.Less(t, time.Since(startTime), pluginWaitDelay)
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package custom
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the plugin in its own process group, so that the
+// processes it spawns are killed along with it once the context is done
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
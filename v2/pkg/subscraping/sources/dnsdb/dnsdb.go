@@ -34,31 +34,39 @@ type safResponse struct {
 }
 
 type dnsdbObj struct {
-	Name string `json:"rrname"`
+	Name          string `json:"rrname"`
+	TimeFirst     int64  `json:"time_first"`
+	TimeLast      int64  `json:"time_last"`
+	ZoneTimeFirst int64  `json:"zone_time_first"`
+	ZoneTimeLast  int64  `json:"zone_time_last"`
+}
+
+// metadata returns the time range the rrset was observed in, either by
+// passive sensors or in zone files
+func (obj dnsdbObj) metadata() *subscraping.Metadata {
+	metadata := &subscraping.Metadata{}
+	for _, timeRange := range [][2]int64{{obj.TimeFirst, obj.TimeLast}, {obj.ZoneTimeFirst, obj.ZoneTimeLast}} {
+		if timeRange[0] > 0 && timeRange[1] > 0 {
+			metadata.Merge(&subscraping.Metadata{FirstSeen: time.Unix(timeRange[0], 0).UTC(), LastSeen: time.Unix(timeRange[1], 0).UTC()})
+		}
+	}
+	return metadata
 }
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   uint64
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		sourceName := s.Name()
+		var resultsCount uint64
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, sourceName)
 		if randomApiKey == "" {
@@ -73,7 +81,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		offsetMax, err := getMaxOffset(ctx, session, headers)
 		if err != nil {
 			results <- subscraping.Result{Source: sourceName, Type: subscraping.Error, Error: err}
-			s.errors++
 			return
 		}
 
@@ -90,7 +97,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 			resp, err := session.Get(ctx, url, "", headers)
 			if err != nil {
 				results <- subscraping.Result{Source: sourceName, Type: subscraping.Error, Error: err}
-				s.errors++
 				session.DiscardHTTPResponse(resp)
 				return
 			}
@@ -103,7 +109,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 					break
 				} else if err != nil {
 					results <- subscraping.Result{Source: sourceName, Type: subscraping.Error, Error: err}
-					s.errors++
 					resp.Body.Close()
 					return
 				}
@@ -112,7 +117,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 				err = jsoniter.Unmarshal(n, &response)
 				if err != nil {
 					results <- subscraping.Result{Source: sourceName, Type: subscraping.Error, Error: err}
-					s.errors++
 					resp.Body.Close()
 					return
 				}
@@ -127,8 +131,9 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 					if response.Obj.Name != "" {
 						results <- subscraping.Result{
 							Source: sourceName, Type: subscraping.Subdomain, Value: strings.TrimSuffix(response.Obj.Name, "."),
+							Metadata: response.Obj.metadata(),
 						}
-						s.results++
+						resultsCount++
 					}
 				} else if respCond != "begin" {
 					// if the respCond is not "", "ongoing", or "begin", then it is a terminating condition, so break out of the loop
@@ -142,16 +147,15 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 			// 3. anything else - This is an error and should be reported to the user. The user can then decide to use the results up to this
 			// point or discard and retry.
 			if respCond == "limited" {
-				if offsetMax != 0 && s.results <= offsetMax {
-					// Reset done to false to get more results with an offset query parameter set to s.results
-					queryParams.Set("offset", strconv.FormatUint(s.results, 10))
+				if offsetMax != 0 && resultsCount <= offsetMax {
+					// Reset done to false to get more results with an offset query parameter set to resultsCount
+					queryParams.Set("offset", strconv.FormatUint(resultsCount, 10))
 					continue
 				}
 			} else if respCond != "succeeded" {
 				// DNSDB's terminating jsonl object's cond is not "limited" or succeeded" (#3), this is an error, notify the user.
 				err = fmt.Errorf("%s terminated with condition: %s", sourceName, respCond)
 				results <- subscraping.Result{Source: sourceName, Type: subscraping.Error, Error: err}
-				s.errors++
 			}
 
 			resp.Body.Close()
@@ -183,15 +187,6 @@ func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
 
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   int(s.results),
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
-
 func getMaxOffset(ctx context.Context, session *subscraping.Session, headers map[string]string) (uint64, error) {
 	var offsetMax uint64
 	url := fmt.Sprintf("%s/rate_limit", urlBase)
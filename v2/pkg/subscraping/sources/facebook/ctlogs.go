@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 	"github.com/projectdiscovery/gologger"
@@ -70,67 +69,55 @@ func (k *apiKey) IsValid() bool {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []apiKey
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []apiKey
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	if len(s.apiKeys) == 0 {
-		s.skipped = true
+		session.Statistics.SetSkipped(s.Name())
 		close(results)
 		return results
 	}
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		key := subscraping.PickRandom(s.apiKeys, s.Name())
-		domainsURL := fmt.Sprintf(domainsUrl, key.AccessToken, domain)
-
-		for {
-			// unfortunately, this cannot be parllelized since pagination is cursor based
+		// unfortunately, this cannot be parllelized since pagination is cursor based
+		paginator := subscraping.Paginator{Style: subscraping.Cursor, PageLimits: subscraping.PageLimits{MaxPages: subscraping.UnlimitedPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
+			// cursor includes api key so no need to update it
+			domainsURL := fmt.Sprintf(domainsUrl, key.AccessToken, domain)
+			if page.Cursor != "" {
+				domainsURL = updateParamInURL(page.Cursor, "limit", domainsPerPage)
+			}
 			resp, err := session.Get(ctx, domainsURL, "", nil)
 			if err != nil {
-				s.errors++
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				return
+				session.DiscardHTTPResponse(resp)
+				return subscraping.PageResponse{}, err
 			}
 			bin, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
 			if err != nil {
-				s.errors++
 				gologger.Verbose().Msgf("failed to read response body: %s\n", err)
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				return
+				return subscraping.PageResponse{}, err
 			}
-			resp.Body.Close()
 			response := &response{}
 			if err := json.Unmarshal(bin, response); err != nil {
-				s.errors++
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: errorutil.NewWithErr(err).Msgf("failed to unmarshal response: %s", string(bin))}
-				return
+				return subscraping.PageResponse{}, errorutil.NewWithErr(err).Msgf("failed to unmarshal response: %s", string(bin))
 			}
 			for _, v := range response.Data {
 				for _, domain := range v.Domains {
 					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: domain}
-					s.results++
 				}
 			}
-			if response.Paging.Next == "" {
-				break
-			}
-			// cursor includes api key so no need to update it
-			domainsURL = updateParamInURL(response.Paging.Next, "limit", domainsPerPage)
+			return subscraping.PageResponse{Results: len(response.Data), Next: response.Paging.Next}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 	}()
 
@@ -177,15 +164,6 @@ func (s *Source) AddApiKeys(keys []string) {
 }
 
 // Statistics returns the statistics for the source
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
-
 func updateParamInURL(url, param, value string) string {
 	urlx, err := urlutil.Parse(url)
 	if err != nil {
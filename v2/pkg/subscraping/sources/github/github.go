@@ -12,7 +12,6 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -39,28 +38,19 @@ type response struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		if len(s.apiKeys) == 0 {
 			gologger.Debug().Msgf("Cannot use the %s source because there was no key defined for it.", s.Name())
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
@@ -90,7 +80,6 @@ func (s *Source) enumerate(ctx context.Context, searchURL string, domainRegexp *
 	isForbidden := resp != nil && resp.StatusCode == http.StatusForbidden
 	if err != nil && !isForbidden {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		session.DiscardHTTPResponse(resp)
 		return
 	}
@@ -111,7 +100,6 @@ func (s *Source) enumerate(ctx context.Context, searchURL string, domainRegexp *
 	err = jsoniter.NewDecoder(resp.Body).Decode(&data)
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		resp.Body.Close()
 		return
 	}
@@ -121,7 +109,6 @@ func (s *Source) enumerate(ctx context.Context, searchURL string, domainRegexp *
 	err = s.proccesItems(ctx, data.Items, domainRegexp, s.Name(), session, results)
 	if err != nil {
 		results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-		s.errors++
 		return
 	}
 
@@ -133,7 +120,6 @@ func (s *Source) enumerate(ctx context.Context, searchURL string, domainRegexp *
 			nextURL, err := url.QueryUnescape(link.URL)
 			if err != nil {
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
 				return
 			}
 			s.enumerate(ctx, nextURL, domainRegexp, tokens, session, results)
@@ -170,7 +156,6 @@ func (s *Source) proccesItems(ctx context.Context, items []item, domainRegexp *r
 					}
 					for _, subdomain := range domainRegexp.FindAllString(normalizeContent(line), -1) {
 						results <- subscraping.Result{Source: name, Type: subscraping.Subdomain, Value: subdomain}
-						s.results++
 					}
 				}
 				resp.Body.Close()
@@ -180,7 +165,6 @@ func (s *Source) proccesItems(ctx context.Context, items []item, domainRegexp *r
 			for _, textMatch := range responseItem.TextMatches {
 				for _, subdomain := range domainRegexp.FindAllString(normalizeContent(textMatch.Fragment), -1) {
 					results <- subscraping.Result{Source: name, Type: subscraping.Subdomain, Value: subdomain}
-					s.results++
 				}
 			}
 		}(responseItem)
@@ -238,12 +222,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 	jsoniter "github.com/json-iterator/go"
 )
 
+const (
+	pageSize = 100
+	maxPages = 10
+)
+
 type hunterResp struct {
 	Code    int        `json:"code"`
 	Data    hunterData `json:"data"`
@@ -23,6 +28,22 @@ type infoArr struct {
 	Port     int    `json:"port"`
 	Domain   string `json:"domain"`
 	Protocol string `json:"protocol"`
+	UpdateAt string `json:"updated_at"`
+}
+
+// metadata returns the address, port and update date of the hunter asset
+func (info infoArr) metadata() *subscraping.Metadata {
+	metadata := &subscraping.Metadata{}
+	if info.IP != "" {
+		metadata.IPs = []string{info.IP}
+	}
+	if info.Port > 0 {
+		metadata.Ports = []int{info.Port}
+	}
+	if updatedAt, err := time.Parse(time.DateOnly, info.UpdateAt); err == nil {
+		metadata.LastSeen = updatedAt
+	}
+	return metadata
 }
 
 type hunterData struct {
@@ -32,122 +53,57 @@ type hunterData struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 	var responseStrings []string
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
-		var pages = 1
 		// hunter api doc https://hunter.qianxin.com/home/helpCenter?r=5-1-2
 		qbase64 := base64.URLEncoding.EncodeToString([]byte(fmt.Sprintf("domain=\"%s\"", domain)))
-		resp, err := session.SimpleGet(ctx, fmt.Sprintf("https://hunter.qianxin.com/openApi/search?api-key=%s&search=%s&page=1&page_size=100&is_web=3", randomApiKey, qbase64))
-		if err != nil && resp == nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
-			session.DiscardHTTPResponse(resp)
-			return
-		}
-
-		var response hunterResp
-		// 先读取响应体内容
-		bodyBytes, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
-			return
-		}
-		defer resp.Body.Close() // 确保 Body 被关
-
-		err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(bodyBytes, &response)
-		if err != nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
-			return
-		}
+		paginator := subscraping.Paginator{Style: subscraping.PageNumber, Start: 1, PageLimits: subscraping.PageLimits{MaxPages: maxPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
+			resp, err := session.SimpleGet(ctx, fmt.Sprintf("https://hunter.qianxin.com/openApi/search?api-key=%s&search=%s&page=%d&page_size=%d&is_web=3", randomApiKey, qbase64, page.Number, pageSize))
+			if err != nil {
+				session.DiscardHTTPResponse(resp)
+				return subscraping.PageResponse{}, err
+			}
+			bodyBytes, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return subscraping.PageResponse{}, err
+			}
 
-		if response.Code == 401 || response.Code == 400 || response.Code == 4024 {
-			results <- subscraping.Result{
-				Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("%s", response.Message),
+			var response hunterResp
+			if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(bodyBytes, &response); err != nil {
+				return subscraping.PageResponse{}, err
+			}
+			// code 4024 means that the api key may have insufficient balance
+			if response.Code == 401 || response.Code == 400 || response.Code == 4024 {
+				return subscraping.PageResponse{}, fmt.Errorf("%s", response.Message)
 			}
-			s.errors++
-			return
-		}
 
-		if response.Data.Total > 0 {
 			for _, hunterInfo := range response.Data.InfoArr {
-				subdomain := hunterInfo.Domain
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-				s.results++
+				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: hunterInfo.Domain, Metadata: hunterInfo.metadata()}
 			}
-			responseStrings = append(responseStrings, string(bodyBytes))
-		}
-		//count pages
-		pages = int(response.Data.Total/100) + 1
-		if pages > 1 {
-			for currentPage := 2; currentPage <= pages; currentPage++ {
-				time.Sleep(5 * time.Second)
-				resp, err = session.SimpleGet(ctx, fmt.Sprintf("https://hunter.qianxin.com/openApi/search?api-key=%s&search=%s&page=%d&page_size=100&is_web=3", randomApiKey, qbase64, currentPage))
-				if err != nil && resp == nil {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-					s.errors++
-					session.DiscardHTTPResponse(resp)
-					continue
-				}
-				// 先读取响应体内容
-				bodyBytes, err = ioutil.ReadAll(resp.Body)
-				if err != nil {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-					s.errors++
-					return
-				}
-				defer resp.Body.Close() // 确保 Body 被关
-
-				err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(bodyBytes, &response)
-				if err != nil {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-					s.errors++
-					continue
-				}
-
-				//if code == 4024 this means that your api may have insufficient balance
-				if response.Code == 401 || response.Code == 400 || response.Code == 4024 {
-					results <- subscraping.Result{
-						Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("%s", response.Message),
-					}
-					s.errors++
-					continue
-				}
-
-				if response.Data.Total > 0 {
-					for _, hunterInfo := range response.Data.InfoArr {
-						subdomain := hunterInfo.Domain
-						results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-						s.results++
-					}
-					responseStrings = append(responseStrings, string(bodyBytes))
-				}
+			if len(response.Data.InfoArr) > 0 {
+				responseStrings = append(responseStrings, string(bodyBytes))
 			}
+			return subscraping.PageResponse{Results: len(response.Data.InfoArr), Total: response.Data.Total}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 		subscraping.WriteResponseData(responseStrings, s.Name(), session.RespFileDirectory)
 	}()
@@ -175,12 +131,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -12,24 +12,15 @@ import (
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 		// Default headers
 		headers := map[string]string{
 			"accept": "application/json",
@@ -43,13 +34,11 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		resp, err := session.Get(ctx, "https://leakix.net/api/subdomains/"+domain, "", headers)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			return
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode != 200 {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("request failed with status %d", resp.StatusCode)}
-			s.errors++
 			return
 		}
 		// Parse and return results
@@ -58,14 +47,12 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		err = decoder.Decode(&subdomains)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			return
 		}
 		for _, result := range subdomains {
 			results <- subscraping.Result{
 				Source: s.Name(), Type: subscraping.Subdomain, Value: result.Subdomain,
 			}
-			s.results++
 		}
 	}()
 	return results
@@ -92,15 +79,6 @@ func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
 
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
-
 type subResponse struct {
 	Subdomain   string    `json:"subdomain"`
 	DistinctIps int       `json:"distinct_ips"`
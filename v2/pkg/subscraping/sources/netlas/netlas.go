@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
@@ -36,23 +35,14 @@ type DomainsCountResponse struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		// To get count of domains
 		endpoint := "https://app.netlas.io/api/domains_count/"
@@ -70,11 +60,9 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			return
 		} else if resp.StatusCode != 200 {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("request rate limited with status code %d", resp.StatusCode)}
-			s.errors++
 			return
 		}
 		defer resp.Body.Close()
@@ -82,7 +70,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("error reading ressponse body")}
-			s.errors++
 			return
 		}
 
@@ -91,7 +78,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		err = json.Unmarshal(body, &domainsCount)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			return
 		}
 
@@ -108,7 +94,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		jsonRequestBody, err := json.Marshal(requestBody)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("error marshaling request body")}
-			s.errors++
 			return
 		}
 
@@ -121,20 +106,17 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 			"Content-Type": "application/json"}, strings.NewReader(string(jsonRequestBody)), subscraping.BasicAuth{})
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			return
 		}
 		defer resp.Body.Close()
 		body, err = io.ReadAll(resp.Body)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("error reading ressponse body")}
-			s.errors++
 			return
 		}
 
 		if resp.StatusCode == 429 {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("request rate limited with status code %d", resp.StatusCode)}
-			s.errors++
 			return
 		}
 
@@ -143,7 +125,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 		err = json.Unmarshal(body, &data)
 		if err != nil {
 			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
 			return
 		}
 
@@ -151,7 +132,6 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 			results <- subscraping.Result{
 				Source: s.Name(), Type: subscraping.Subdomain, Value: item.Data.Domain,
 			}
-			s.results++
 		}
 
 	}()
@@ -179,12 +159,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -5,15 +5,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"strings"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
 
+const (
+	pageSize = 100
+	maxPages = 10
+)
+
 type quakeResults struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -33,132 +37,61 @@ type quakeResults struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 	var responseStrings []string
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
-			return
-		}
-		var pages = 1
-		var pagesize = 100
-		// quake api doc https://quake.360.cn/quake/#/help remove "include":["service.http.host"], can get all data
-		var requestBody = []byte(fmt.Sprintf(`{"query":"domain: %s", "latest": true, "start":0, "size":%d, "latest":true}`, domain, pagesize))
-		resp, err := session.Post(ctx, "https://quake.360.net/api/v3/search/quake_service", "", map[string]string{
-			"Content-Type": "application/json", "X-QuakeToken": randomApiKey,
-		}, bytes.NewReader(requestBody))
-		if err != nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
-			session.DiscardHTTPResponse(resp)
-			return
-		}
-
-		var response quakeResults
-		// 先读取响应体内容
-		bodyBytes, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
-		defer resp.Body.Close() // 确保 Body 被关
+		paginator := subscraping.Paginator{Style: subscraping.Offset, Size: pageSize, PageLimits: subscraping.PageLimits{MaxPages: maxPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
+			// quake api doc https://quake.360.cn/quake/#/help remove "include":["service.http.host"], can get all data
+			requestBody := []byte(fmt.Sprintf(`{"query":"domain: %s", "latest": true, "start":%d, "size":%d}`, domain, page.Offset, pageSize))
+			resp, err := session.Post(ctx, "https://quake.360.net/api/v3/search/quake_service", "", map[string]string{
+				"Content-Type": "application/json", "X-QuakeToken": randomApiKey,
+			}, bytes.NewReader(requestBody))
+			if err != nil {
+				session.DiscardHTTPResponse(resp)
+				return subscraping.PageResponse{}, err
+			}
+			bodyBytes, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return subscraping.PageResponse{}, err
+			}
 
-		err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(bodyBytes, &response)
-		if err != nil {
-			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-			s.errors++
-			return
-		}
-		if response.Code != 0 {
-			results <- subscraping.Result{
-				Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("%s", response.Message),
+			var response quakeResults
+			if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(bodyBytes, &response); err != nil {
+				return subscraping.PageResponse{}, err
+			}
+			if response.Code != 0 {
+				return subscraping.PageResponse{}, fmt.Errorf("%s", response.Message)
 			}
-			s.errors++
-			return
-		}
 
-		if response.Meta.Pagination.Total > 0 {
 			for _, quakeDomain := range response.Data {
 				subdomain := quakeDomain.Service.HTTP.Host
 				if strings.ContainsAny(subdomain, "暂无权限") {
 					subdomain = ""
 				}
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-				s.results++
 			}
-			responseStrings = append(responseStrings, string(bodyBytes))
-		}
-		pages = int(response.Meta.Pagination.Total/pagesize) + 1
-		if pages > 1 {
-			for currentPage := 2; currentPage <= pages; currentPage++ {
-				//限速
-				time.Sleep(10 * time.Second)
-				var start = (currentPage - 1) * pagesize
-				requestBody = []byte(fmt.Sprintf(`{"query":"domain: %s","latest": true, "start":%d, "size":%d ,"latest":true}`, domain, start, pagesize))
-				resp, err = session.Post(ctx, "https://quake.360.net/api/v3/search/quake_service", "", map[string]string{
-					"Content-Type": "application/json", "X-QuakeToken": randomApiKey,
-				}, bytes.NewReader(requestBody))
-				if err != nil {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-					s.errors++
-					session.DiscardHTTPResponse(resp)
-					continue
-				}
-				//读取响应信息
-				bodyBytes, err = ioutil.ReadAll(resp.Body)
-				if err != nil {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-					s.errors++
-					resp.Body.Close()
-					continue
-				}
-				err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(bodyBytes, &response)
-				if err != nil {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-					s.errors++
-					continue
-				}
-				resp.Body.Close()
-
-				if response.Code != 0 {
-					results <- subscraping.Result{
-						Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("%s", response.Message),
-					}
-					s.errors++
-					continue
-				}
-
-				if response.Meta.Pagination.Total > 0 {
-					for _, quakeDomain := range response.Data {
-						subdomain := quakeDomain.Service.HTTP.Host
-						if strings.ContainsAny(subdomain, "暂无权限") {
-							subdomain = ""
-						}
-						results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-						s.results++
-					}
-					responseStrings = append(responseStrings, string(bodyBytes))
-				}
+			if len(response.Data) > 0 {
+				responseStrings = append(responseStrings, string(bodyBytes))
 			}
+			return subscraping.PageResponse{Results: len(response.Data), Total: response.Meta.Pagination.Total}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 		subscraping.WriteResponseData(responseStrings, s.Name(), session.RespFileDirectory)
 	}()
@@ -185,12 +118,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -27,71 +26,55 @@ type response struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
-		var scrollId string
 		headers := map[string]string{"Content-Type": "application/json", "APIKEY": randomApiKey}
 
-		for {
+		paginator := subscraping.Paginator{Style: subscraping.Cursor, PageLimits: subscraping.PageLimits{MaxPages: subscraping.UnlimitedPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
 			var resp *http.Response
 			var err error
 
-			if scrollId == "" {
+			if page.Cursor == "" {
 				var requestBody = []byte(fmt.Sprintf(`{"query":"apex_domain='%s'"}`, domain))
 				resp, err = session.Post(ctx, "https://api.securitytrails.com/v1/domains/list?include_ips=false&scroll=true", "",
 					headers, bytes.NewReader(requestBody))
 			} else {
-				resp, err = session.Get(ctx, fmt.Sprintf("https://api.securitytrails.com/v1/scroll/%s", scrollId), "", headers)
+				resp, err = session.Get(ctx, fmt.Sprintf("https://api.securitytrails.com/v1/scroll/%s", page.Cursor), "", headers)
 			}
 
 			if err != nil && ptr.Safe(resp).StatusCode == 403 {
+				session.DiscardHTTPResponse(resp)
 				resp, err = session.Get(ctx, fmt.Sprintf("https://api.securitytrails.com/v1/domain/%s/subdomains", domain), "", headers)
 			}
 
 			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
 				session.DiscardHTTPResponse(resp)
-				return
+				return subscraping.PageResponse{}, err
 			}
+			defer resp.Body.Close()
 
 			var securityTrailsResponse response
-			err = jsoniter.NewDecoder(resp.Body).Decode(&securityTrailsResponse)
-			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				resp.Body.Close()
-				return
+			if err := jsoniter.NewDecoder(resp.Body).Decode(&securityTrailsResponse); err != nil {
+				return subscraping.PageResponse{}, err
 			}
 
-			resp.Body.Close()
-
 			for _, record := range securityTrailsResponse.Records {
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: record.Hostname}
-				s.results++
 			}
 
 			for _, subdomain := range securityTrailsResponse.Subdomains {
@@ -101,14 +84,15 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 					subdomain = subdomain + "." + domain
 				}
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain}
-				s.results++
 			}
 
-			scrollId = securityTrailsResponse.Meta.ScrollID
-
-			if scrollId == "" {
-				break
-			}
+			return subscraping.PageResponse{
+				Results: len(securityTrailsResponse.Records) + len(securityTrailsResponse.Subdomains),
+				Next:    securityTrailsResponse.Meta.ScrollID,
+			}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 	}()
 
@@ -135,12 +119,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -4,7 +4,6 @@ package shodan
 import (
 	"context"
 	"fmt"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -13,11 +12,7 @@ import (
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 type dnsdbLookupResponse struct {
@@ -31,47 +26,32 @@ type dnsdbLookupResponse struct {
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
-		page := 1
-		for {
-
-			searchURL := fmt.Sprintf("https://api.shodan.io/dns/domain/%s?key=%s&page=%d", domain, randomApiKey, page)
+		paginator := subscraping.Paginator{Style: subscraping.PageNumber, Start: 1, PageLimits: subscraping.PageLimits{MaxPages: subscraping.UnlimitedPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
+			searchURL := fmt.Sprintf("https://api.shodan.io/dns/domain/%s?key=%s&page=%d", domain, randomApiKey, page.Number)
 			resp, err := session.SimpleGet(ctx, searchURL)
 			if err != nil {
 				session.DiscardHTTPResponse(resp)
-				return
+				return subscraping.PageResponse{}, err
 			}
-
 			defer resp.Body.Close()
 
 			var response dnsdbLookupResponse
-			err = jsoniter.NewDecoder(resp.Body).Decode(&response)
-			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				return
+			if err := jsoniter.NewDecoder(resp.Body).Decode(&response); err != nil {
+				return subscraping.PageResponse{}, err
 			}
-
 			if response.Error != "" {
-				results <- subscraping.Result{
-					Source: s.Name(), Type: subscraping.Error, Error: fmt.Errorf("%v", response.Error),
-				}
-				s.errors++
-				return
+				return subscraping.PageResponse{}, fmt.Errorf("%v", response.Error)
 			}
 
 			for _, data := range response.Subdomains {
@@ -79,13 +59,11 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 				results <- subscraping.Result{
 					Source: s.Name(), Type: subscraping.Subdomain, Value: value,
 				}
-				s.results++
-			}
-
-			if !response.More {
-				break
 			}
-			page++
+			return subscraping.PageResponse{Results: len(response.Subdomains), More: response.More}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 	}()
 
@@ -112,12 +90,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
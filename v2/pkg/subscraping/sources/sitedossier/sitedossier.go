@@ -7,7 +7,6 @@ import (
 	"io"
 	"net/http"
 	"regexp"
-	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
@@ -19,23 +18,14 @@ const SleepRandIntn = 5
 var reNext = regexp.MustCompile(`<a href="([A-Za-z0-9/.]+)"><b>`)
 
 // Source is the passive scraping agent
-type Source struct {
-	timeTaken time.Duration
-	errors    int
-	results   int
-}
+type Source struct{}
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		s.enumerate(ctx, session, fmt.Sprintf("http://www.sitedossier.com/parentdomain/%s", domain), results)
 	}()
@@ -54,7 +44,6 @@ func (s *Source) enumerate(ctx context.Context, session *subscraping.Session, ba
 	isnotfound := resp != nil && resp.StatusCode == http.StatusNotFound
 	if err != nil && !isnotfound {
 		results <- subscraping.Result{Source: "sitedossier", Type: subscraping.Error, Error: err}
-		s.errors++
 		session.DiscardHTTPResponse(resp)
 		return
 	}
@@ -62,7 +51,6 @@ func (s *Source) enumerate(ctx context.Context, session *subscraping.Session, ba
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		results <- subscraping.Result{Source: "sitedossier", Type: subscraping.Error, Error: err}
-		s.errors++
 		resp.Body.Close()
 		return
 	}
@@ -71,7 +59,6 @@ func (s *Source) enumerate(ctx context.Context, session *subscraping.Session, ba
 	src := string(body)
 	for _, subdomain := range session.Extractor.Extract(src) {
 		results <- subscraping.Result{Source: "sitedossier", Type: subscraping.Subdomain, Value: subdomain}
-		s.results++
 	}
 
 	match := reNext.FindStringSubmatch(src)
@@ -100,11 +87,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(_ []string) {
 	// no key needed
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-	}
-}
@@ -4,7 +4,6 @@ package virustotal
 import (
 	"context"
 	"fmt"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -26,60 +25,45 @@ type Meta struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
 			return
 		}
-		var cursor string = ""
-		for {
+		paginator := subscraping.Paginator{Style: subscraping.Cursor, PageLimits: subscraping.PageLimits{MaxPages: subscraping.UnlimitedPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
 			var url string = fmt.Sprintf("https://www.virustotal.com/api/v3/domains/%s/subdomains?limit=40", domain)
-			if cursor != "" {
-				url = fmt.Sprintf("%s&cursor=%s", url, cursor)
+			if page.Cursor != "" {
+				url = fmt.Sprintf("%s&cursor=%s", url, page.Cursor)
 			}
 			resp, err := session.Get(ctx, url, "", map[string]string{"x-apikey": randomApiKey})
 			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
 				session.DiscardHTTPResponse(resp)
-				return
+				return subscraping.PageResponse{}, err
 			}
 			defer resp.Body.Close()
 
 			var data response
-			err = jsoniter.NewDecoder(resp.Body).Decode(&data)
-			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				return
+			if err := jsoniter.NewDecoder(resp.Body).Decode(&data); err != nil {
+				return subscraping.PageResponse{}, err
 			}
 
 			for _, subdomain := range data.Data {
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: subdomain.Id}
-				s.results++
-			}
-			cursor = data.Meta.Cursor
-			if cursor == "" {
-				break
 			}
+			return subscraping.PageResponse{Results: len(data.Data), Next: data.Meta.Cursor}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 	}()
 
@@ -106,12 +90,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/YouChenJun/subfinder-plus/pkg/subscraping"
 )
@@ -23,34 +22,25 @@ type zoomeyeResults struct {
 
 // Source is the passive scraping agent
 type Source struct {
-	apiKeys   []string
-	timeTaken time.Duration
-	errors    int
-	results   int
-	skipped   bool
+	apiKeys []string
 }
 
 // Run function returns all subdomains found with the service
 func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Session) <-chan subscraping.Result {
 	results := make(chan subscraping.Result)
-	s.errors = 0
-	s.results = 0
 
 	go func() {
-		defer func(startTime time.Time) {
-			s.timeTaken = time.Since(startTime)
-			close(results)
-		}(time.Now())
+		defer close(results)
 
 		randomApiKey := subscraping.PickRandom(s.apiKeys, s.Name())
 		if randomApiKey == "" {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 
 		randomApiInfo := strings.Split(randomApiKey, ":")
 		if len(randomApiInfo) != 2 {
-			s.skipped = true
+			session.Statistics.SetSkipped(s.Name())
 			return
 		}
 		host := randomApiInfo[0]
@@ -61,35 +51,31 @@ func (s *Source) Run(ctx context.Context, domain string, session *subscraping.Se
 			"Accept":       "application/json",
 			"Content-Type": "application/json",
 		}
-		var pages = 1
-		for currentPage := 1; currentPage <= pages; currentPage++ {
-			api := fmt.Sprintf("https://api.%s/domain/search?q=%s&type=1&s=1000&page=%d", host, domain, currentPage)
+		paginator := subscraping.Paginator{Style: subscraping.PageNumber, Start: 1, PageLimits: subscraping.PageLimits{MaxPages: subscraping.UnlimitedPages}}
+		err := session.Paginate(ctx, paginator, func(page subscraping.Page) (subscraping.PageResponse, error) {
+			api := fmt.Sprintf("https://api.%s/domain/search?q=%s&type=1&s=1000&page=%d", host, domain, page.Number)
 			resp, err := session.Get(ctx, api, "", headers)
-			isForbidden := resp != nil && resp.StatusCode == http.StatusForbidden
 			if err != nil {
-				if !isForbidden {
-					results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-					s.errors++
-					session.DiscardHTTPResponse(resp)
+				session.DiscardHTTPResponse(resp)
+				// the api key is not allowed to search the domains, there is nothing to report
+				if resp != nil && resp.StatusCode == http.StatusForbidden {
+					err = nil
 				}
-				return
+				return subscraping.PageResponse{}, err
 			}
+			defer resp.Body.Close()
 
 			var res zoomeyeResults
-			err = json.NewDecoder(resp.Body).Decode(&res)
-
-			if err != nil {
-				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
-				s.errors++
-				_ = resp.Body.Close()
-				return
+			if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				return subscraping.PageResponse{}, err
 			}
-			_ = resp.Body.Close()
-			pages = int(res.Total/1000) + 1
 			for _, r := range res.List {
 				results <- subscraping.Result{Source: s.Name(), Type: subscraping.Subdomain, Value: r.Name}
-				s.results++
 			}
+			return subscraping.PageResponse{Results: len(res.List), Total: res.Total}, nil
+		})
+		if err != nil {
+			results <- subscraping.Result{Source: s.Name(), Type: subscraping.Error, Error: err}
 		}
 	}()
 
@@ -116,12 +102,3 @@ func (s *Source) NeedsKey() bool {
 func (s *Source) AddApiKeys(keys []string) {
 	s.apiKeys = keys
 }
-
-func (s *Source) Statistics() subscraping.Statistics {
-	return subscraping.Statistics{
-		Errors:    s.errors,
-		Results:   s.results,
-		TimeTaken: s.timeTaken,
-		Skipped:   s.skipped,
-	}
-}
@@ -0,0 +1,95 @@
+package subscraping
+
+import (
+	"sync"
+	"time"
+)
+
+// StatisticsCollector gathers the statistics of every source
+// for a single enumeration. It is safe for concurrent use and
+// all its methods are no-op on a nil collector.
+type StatisticsCollector struct {
+	mutex      sync.Mutex
+	statistics map[string]*Statistics
+}
+
+// NewStatisticsCollector creates a new empty statistics collector
+func NewStatisticsCollector() *StatisticsCollector {
+	return &StatisticsCollector{statistics: make(map[string]*Statistics)}
+}
+
+func (c *StatisticsCollector) update(source string, fn func(stats *Statistics)) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats, ok := c.statistics[source]
+	if !ok {
+		stats = &Statistics{}
+		c.statistics[source] = stats
+	}
+	fn(stats)
+}
+
+// AddSource registers a source so that it shows up in the statistics even without results
+func (c *StatisticsCollector) AddSource(source string) {
+	c.update(source, func(*Statistics) {})
+}
+
+// AddResult counts a subdomain returned by the source
+func (c *StatisticsCollector) AddResult(source string) {
+	c.update(source, func(stats *Statistics) { stats.Results++ })
+}
+
+// AddError counts an error returned by the source
+func (c *StatisticsCollector) AddError(source string) {
+	c.update(source, func(stats *Statistics) { stats.Errors++ })
+}
+
+// AddDuplicate counts a subdomain of the source which was already found
+func (c *StatisticsCollector) AddDuplicate(source string) {
+	c.update(source, func(stats *Statistics) { stats.Duplicates++ })
+}
+
+// AddOutOfScope counts a subdomain of the source which does not belong to the domain
+func (c *StatisticsCollector) AddOutOfScope(source string) {
+	c.update(source, func(stats *Statistics) { stats.OutOfScope++ })
+}
+
+// AddFiltered counts a subdomain of the source left out by the match and filter patterns
+func (c *StatisticsCollector) AddFiltered(source string) {
+	c.update(source, func(stats *Statistics) { stats.Filtered++ })
+}
+
+// AddRetry counts a request of the source sent again after a failure
+func (c *StatisticsCollector) AddRetry(source string) {
+	c.update(source, func(stats *Statistics) { stats.Retries++ })
+}
+
+// SetSkipped marks the source as skipped, e.g. because it has no API key
+func (c *StatisticsCollector) SetSkipped(source string) {
+	c.update(source, func(stats *Statistics) { stats.Skipped = true })
+}
+
+// AddTimeTaken records how long the source took to complete,
+// adding up the time of every target the source was run against
+func (c *StatisticsCollector) AddTimeTaken(source string, timeTaken time.Duration) {
+	c.update(source, func(stats *Statistics) { stats.TimeTaken += timeTaken })
+}
+
+// Statistics returns a snapshot of the statistics per source
+func (c *StatisticsCollector) Statistics() map[string]Statistics {
+	statistics := make(map[string]Statistics)
+	if c == nil {
+		return statistics
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for source, stats := range c.statistics {
+		statistics[source] = *stats
+	}
+	return statistics
+}
@@ -0,0 +1,67 @@
+package subscraping
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatisticsCollector(t *testing.T) {
+	collector := NewStatisticsCollector()
+	collector.AddSource("idle")
+	for i := 0; i < 6; i++ {
+		collector.AddResult("crtsh")
+	}
+	collector.AddDuplicate("crtsh")
+	collector.AddDuplicate("crtsh")
+	collector.AddOutOfScope("crtsh")
+	collector.AddFiltered("crtsh")
+	collector.AddError("crtsh")
+	collector.AddRetry("crtsh")
+	collector.AddTimeTaken("crtsh", time.Second)
+	collector.AddTimeTaken("crtsh", 2*time.Second)
+	collector.SetSkipped("github")
+
+	statistics := collector.Statistics()
+	assert.Equal(t, Statistics{
+		TimeTaken:  3 * time.Second,
+		Errors:     1,
+		Results:    6,
+		Duplicates: 2,
+		OutOfScope: 1,
+		Filtered:   1,
+		Retries:    1,
+	}, statistics["crtsh"])
+	assert.Equal(t, 2, statistics["crtsh"].Unique())
+	assert.Equal(t, Statistics{}, statistics["idle"])
+	assert.Equal(t, Statistics{Skipped: true}, statistics["github"])
+
+	// The statistics returned are a snapshot
+	crtsh := statistics["crtsh"]
+	crtsh.Results = 0
+	statistics["crtsh"] = crtsh
+	assert.Equal(t, 6, collector.Statistics()["crtsh"].Results)
+}
+
+func TestStatisticsCollectorConcurrent(t *testing.T) {
+	collector := NewStatisticsCollector()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				collector.AddResult("crtsh")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, collector.Statistics()["crtsh"].Results)
+
+	// A nil collector records nothing
+	var nilCollector *StatisticsCollector
+	nilCollector.AddResult("crtsh")
+	assert.Empty(t, nilCollector.Statistics())
+}
@@ -3,6 +3,7 @@ package subscraping
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/projectdiscovery/ratelimit"
@@ -15,8 +16,15 @@ const (
 	CtxSourceArg CtxArg = "source"
 )
 
+// CustomRateLimit contains the rate limits of the sources, by source name
 type CustomRateLimit struct {
-	Custom mapsutil.SyncLockMap[string, uint]
+	Custom mapsutil.SyncLockMap[string, RateLimit]
+}
+
+// RateLimit is the maximum number of requests sent by a source per duration
+type RateLimit struct {
+	MaxCount uint
+	Duration time.Duration
 }
 
 // BasicAuth request's Authorization header
@@ -27,10 +35,19 @@ type BasicAuth struct {
 
 // Statistics contains statistics about the scraping process
 type Statistics struct {
-	TimeTaken time.Duration
-	Errors    int
-	Results   int
-	Skipped   bool
+	TimeTaken  time.Duration
+	Errors     int
+	Results    int // Results is the number of subdomains returned by the source
+	Duplicates int // Duplicates is the number of results already found before
+	OutOfScope int // OutOfScope is the number of results not belonging to the domain
+	Filtered   int // Filtered is the number of results left out by the match and filter patterns
+	Retries    int // Retries is the number of requests sent again after a failure
+	Skipped    bool
+}
+
+// Unique returns the number of new in scope subdomains found by the source
+func (s Statistics) Unique() int {
+	return s.Results - s.Duplicates - s.OutOfScope - s.Filtered
 }
 
 // Source is an interface inherited by each passive source
@@ -56,9 +73,6 @@ type Source interface {
 	NeedsKey() bool
 
 	AddApiKeys([]string)
-
-	// Statistics returns the scrapping statistics for the source
-	Statistics() Statistics
 }
 
 // SubdomainExtractor is an interface that defines the contract for subdomain extraction.
@@ -76,6 +90,16 @@ type Session struct {
 	// Rate limit instance
 	MultiRateLimiter  *ratelimit.MultiLimiter
 	RespFileDirectory string // RespFileDirectory is the directory to write response files to in case list of domains is given
+	// Statistics collects the statistics of the sources for the current enumeration
+	Statistics *StatisticsCollector
+	// PageLimits contains the page and result limits of the paged sources, by source name
+	PageLimits map[string]PageLimits
+	// RetryPolicy is how the failed requests of the sources are sent again
+	RetryPolicy RetryPolicy
+	// RetryPolicies contains the retry policies replacing the default one, by source name
+	RetryPolicies map[string]RetryPolicy
+	// proxyURL is the proxy the requests and connections of the session go through
+	proxyURL *url.URL
 }
 
 // Result is a result structure returned by a source
@@ -85,6 +109,8 @@ type Result struct {
 	Value    string
 	Response string
 	Error    error
+	// Metadata contains the optional details the source knows about the subdomain
+	Metadata *Metadata
 }
 
 // ResultType is the type of result returned by the source
@@ -76,7 +76,7 @@ func WriteResponseData(response []string, source string, RespFileDirectory strin
 			filename := filepath.Join(RespFileDirectory, source+".json")
 			file, err := createFile(filename, false)
 			if err != nil {
-				fmt.Printf("创建文件失败 [%s]: %w\n", filename, err)
+				fmt.Printf("创建文件失败 [%s]: %v\n", filename, err)
 				continue
 			}
 			w = struct {
@@ -96,7 +96,7 @@ func WriteResponseData(response []string, source string, RespFileDirectory strin
 			line = data + "\n" // 数据无换行，手动添加
 		}
 		if _, err := w.writer.WriteString(line + "\n"); err != nil {
-			fmt.Printf("数据写入失败 [%s]: %w\n", source, err)
+			fmt.Printf("数据写入失败 [%s]: %v\n", source, err)
 			continue
 		}
 	}
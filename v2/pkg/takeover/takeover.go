@@ -0,0 +1,162 @@
+// Package takeover detects the hosts whose CNAME records point at
+// third-party resources which no longer exist and could be claimed.
+package takeover
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/corpix/uarand"
+	"gopkg.in/yaml.v3"
+)
+
+// maxBodySize is the number of bytes of a response body searched for fingerprints
+const maxBodySize = 1 << 20
+
+//go:embed fingerprints.yaml
+var defaultFingerprints []byte
+
+// Fingerprint describes how a dangling CNAME to a service is recognized
+type Fingerprint struct {
+	Service string `yaml:"service"`
+	// CNAME contains the domains the CNAME targets of the service end with
+	CNAME []string `yaml:"cname"`
+	// Fingerprint contains the texts served for a resource of the service which does not exist
+	Fingerprint []string `yaml:"fingerprint"`
+	// NXDomain specifies whether a CNAME target which does not exist can be claimed
+	NXDomain bool `yaml:"nxdomain"`
+}
+
+// Load returns the bundled fingerprints extended with the ones of the file,
+// if one is given. A fingerprint of the file replaces the bundled one of the
+// same service.
+func Load(path string) ([]Fingerprint, error) {
+	fingerprints, err := parse(defaultFingerprints)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundled fingerprints: %s", err)
+	}
+	if path == "" {
+		return fingerprints, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fingerprints in %s: %s", path, err)
+	}
+	index := make(map[string]int, len(fingerprints))
+	for i, fingerprint := range fingerprints {
+		index[strings.ToLower(fingerprint.Service)] = i
+	}
+	for _, fingerprint := range extra {
+		if i, ok := index[strings.ToLower(fingerprint.Service)]; ok {
+			fingerprints[i] = fingerprint
+			continue
+		}
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	return fingerprints, nil
+}
+
+// parse decodes a list of fingerprints, normalizing their CNAME domains
+func parse(content []byte) ([]Fingerprint, error) {
+	var fingerprints []Fingerprint
+	if err := yaml.Unmarshal(content, &fingerprints); err != nil {
+		return nil, err
+	}
+	for i, fingerprint := range fingerprints {
+		if fingerprint.Service == "" || len(fingerprint.CNAME) == 0 {
+			return nil, fmt.Errorf("fingerprint %d needs a service and a cname", i+1)
+		}
+		if !fingerprint.NXDomain && len(fingerprint.Fingerprint) == 0 {
+			return nil, fmt.Errorf("fingerprint of %s needs nxdomain or a fingerprint", fingerprint.Service)
+		}
+		for j, cname := range fingerprint.CNAME {
+			fingerprints[i].CNAME[j] = strings.ToLower(strings.Trim(cname, "."))
+		}
+	}
+	return fingerprints, nil
+}
+
+// Checker checks the CNAME targets of the hosts against the fingerprints
+type Checker struct {
+	Fingerprints []Fingerprint
+	// NXDomain returns true if the name does not exist
+	NXDomain func(name string) (bool, error)
+	// Client fetches the bodies served for the hosts, nil to only check the CNAME targets
+	Client *http.Client
+}
+
+// Check returns the service the host could be taken over through, if any
+func (c *Checker) Check(ctx context.Context, host string, cnames []string) (string, bool) {
+	for _, cname := range cnames {
+		target := strings.ToLower(strings.TrimSuffix(cname, "."))
+		fingerprint := c.match(target)
+		if fingerprint == nil {
+			continue
+		}
+		if fingerprint.NXDomain && c.NXDomain != nil {
+			if nxdomain, err := c.NXDomain(target); err == nil && nxdomain {
+				return fingerprint.Service, true
+			}
+		}
+		if len(fingerprint.Fingerprint) > 0 && c.Client != nil && c.bodyMatches(ctx, host, fingerprint.Fingerprint) {
+			return fingerprint.Service, true
+		}
+	}
+	return "", false
+}
+
+// match returns the fingerprint of the service the CNAME target belongs to
+func (c *Checker) match(target string) *Fingerprint {
+	for i, fingerprint := range c.Fingerprints {
+		for _, domain := range fingerprint.CNAME {
+			if target == domain || strings.HasSuffix(target, "."+domain) {
+				return &c.Fingerprints[i]
+			}
+		}
+	}
+	return nil
+}
+
+// bodyMatches returns true if the body served for the host over HTTP or HTTPS contains one of the texts
+func (c *Checker) bodyMatches(ctx context.Context, host string, texts []string) bool {
+	for _, scheme := range []string{"http", "https"} {
+		body, err := c.fetch(ctx, scheme+"://"+host)
+		if err != nil {
+			continue
+		}
+		for _, text := range texts {
+			if bytes.Contains(body, []byte(text)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fetch returns the beginning of the body served at the URL
+func (c *Checker) fetch(ctx context.Context, requestURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", uarand.GetRandom())
+	req.Header.Set("Accept", "*/*")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
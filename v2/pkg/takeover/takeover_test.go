@@ -0,0 +1,84 @@
+package takeover
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	bundled, err := Load("")
+	require.Nil(t, err)
+	require.NotEmpty(t, bundled)
+
+	path := filepath.Join(t.TempDir(), "fingerprints.yaml")
+	require.Nil(t, os.WriteFile(path, []byte(`
+- service: github pages
+  cname: [Example.GitHub.io.]
+  fingerprint: [gone]
+- service: Internal PaaS
+  cname: [paas.example.net]
+  nxdomain: true
+`), 0644))
+	fingerprints, err := Load(path)
+	require.Nil(t, err)
+	require.Len(t, fingerprints, len(bundled)+1)
+	assert.Equal(t, Fingerprint{Service: "Internal PaaS", CNAME: []string{"paas.example.net"}, NXDomain: true}, fingerprints[len(fingerprints)-1])
+	for _, fingerprint := range fingerprints {
+		if fingerprint.Service == "github pages" {
+			assert.Equal(t, []string{"example.github.io"}, fingerprint.CNAME)
+		}
+	}
+
+	require.Nil(t, os.WriteFile(path, []byte("- service: Incomplete\n  cname: [example.net]\n"), 0644))
+	_, err = Load(path)
+	assert.NotNil(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Host == "assets.example.com" {
+			http.Error(w, "<Code>NoSuchBucket</Code>", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	// Every host is served by the test server
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}}
+
+	checker := &Checker{
+		Fingerprints: []Fingerprint{
+			{Service: "Aliyun OSS", CNAME: []string{"aliyuncs.com"}, Fingerprint: []string{"NoSuchBucket"}},
+			{Service: "Microsoft Azure", CNAME: []string{"azurewebsites.net"}, NXDomain: true},
+		},
+		NXDomain: func(name string) (bool, error) { return name == "gone.azurewebsites.net", nil },
+		Client:   client,
+	}
+
+	tests := []struct {
+		host    string
+		cnames  []string
+		service string
+	}{
+		{"app.example.com", []string{"gone.azurewebsites.net."}, "Microsoft Azure"},
+		{"live.example.com", []string{"live.azurewebsites.net"}, ""},
+		{"assets.example.com", []string{"assets.oss-cn-hangzhou.aliyuncs.com"}, "Aliyun OSS"},
+		{"static.example.com", []string{"static.oss-cn-hangzhou.aliyuncs.com"}, ""},
+		{"www.example.com", []string{"www.example.net"}, ""},
+	}
+	for _, test := range tests {
+		service, ok := checker.Check(context.Background(), test.host, test.cnames)
+		assert.Equal(t, test.service != "", ok, test.host)
+		assert.Equal(t, test.service, service, test.host)
+	}
+}
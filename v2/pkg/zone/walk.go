@@ -0,0 +1,200 @@
+package zone
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/xid"
+)
+
+// NSEC3Hash is a hashed name of a zone signed with NSEC3
+type NSEC3Hash struct {
+	Hash       string
+	Next       string
+	Salt       string
+	Iterations uint16
+}
+
+// Format returns the hash in the format cracked offline by hashcat (mode 8300)
+func (h NSEC3Hash) Format(zone string) string {
+	return fmt.Sprintf("%s:.%s:%s:%d", h.Hash, strings.TrimSuffix(zone, "."), h.Salt, h.Iterations)
+}
+
+// WalkResult contains the names of a zone signed with NSEC,
+// or the hashed names of a zone signed with NSEC3
+type WalkResult struct {
+	Names  []string
+	Hashes []NSEC3Hash
+}
+
+// Walk enumerates the names of a DNSSEC signed zone from the nameserver.
+// The NSEC chain of the zone is followed from its apex until it loops back,
+// while the hashes of a zone signed with NSEC3 are collected by querying
+// random names until the chain of hashes is complete. At most limit queries
+// are sent. A zone which is not signed returns an empty result.
+func Walk(ctx context.Context, nameserver, zone string, timeout time.Duration, limit int) (*WalkResult, error) {
+	w := &walker{nameserver: nameserver, client: &dns.Client{Net: "tcp", Timeout: timeout}}
+	defer w.close()
+
+	apex := dns.Fqdn(strings.ToLower(zone))
+	names := newNameSet(zone)
+	result := &WalkResult{}
+
+	name := apex
+	for queries := 0; queries < limit; queries++ {
+		if ctx.Err() != nil {
+			result.Names = names.list
+			return result, ctx.Err()
+		}
+
+		nsec, nsec3, err := w.next(name)
+		if err != nil {
+			result.Names = names.list
+			return result, err
+		}
+		if nsec == nil {
+			if len(nsec3) > 0 {
+				return w.collectHashes(ctx, apex, nsec3, limit-queries)
+			}
+			break
+		}
+
+		next := strings.ToLower(dns.Fqdn(nsec.NextDomain))
+		if next == apex || !dns.IsSubDomain(apex, next) {
+			break
+		}
+		if _, ok := names.seen[strings.TrimSuffix(next, ".")]; ok {
+			break
+		}
+		names.add(next)
+		name = next
+	}
+	result.Names = names.list
+	return result, nil
+}
+
+// walker sends the queries of a zone walk over a single connection to the nameserver
+type walker struct {
+	nameserver string
+	client     *dns.Client
+	conn       *dns.Conn
+}
+
+// query sends a DNSSEC query to the nameserver, reconnecting once if the connection was closed
+func (w *walker) query(name string, questionType uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg).SetQuestion(name, questionType)
+	msg.SetEdns0(dns.DefaultMsgSize, true)
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			w.conn, err = w.client.Dial(w.nameserver)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var answer *dns.Msg
+		answer, _, err = w.client.ExchangeWithConn(msg, w.conn)
+		if err == nil {
+			return answer, nil
+		}
+		w.close()
+	}
+	return nil, err
+}
+
+func (w *walker) close() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// next returns the NSEC record of the name. It is asked for directly first,
+// then looked for in the denial of existence of the name right after it,
+// along with the NSEC3 records of the zone if it is signed with NSEC3.
+func (w *walker) next(name string) (*dns.NSEC, []*dns.NSEC3, error) {
+	answer, err := w.query(name, dns.TypeNSEC)
+	if err != nil {
+		return nil, nil, err
+	}
+	if nsec := findNSEC(answer.Answer, name); nsec != nil {
+		return nsec, nil, nil
+	}
+
+	answer, err = w.query(`\000.`+name, dns.TypeA)
+	if err != nil {
+		return nil, nil, err
+	}
+	return findNSEC(answer.Ns, name), findNSEC3(answer.Ns), nil
+}
+
+// collectHashes queries random names of the zone and collects the NSEC3 records
+// denying their existence, until every hash of the chain is known
+func (w *walker) collectHashes(ctx context.Context, apex string, records []*dns.NSEC3, limit int) (*WalkResult, error) {
+	hashes := make(map[string]NSEC3Hash)
+	var order []string
+	add := func(records []*dns.NSEC3) {
+		for _, record := range records {
+			hash := strings.ToLower(strings.SplitN(record.Hdr.Name, ".", 2)[0])
+			if _, ok := hashes[hash]; ok {
+				continue
+			}
+			hashes[hash] = NSEC3Hash{Hash: hash, Next: strings.ToLower(record.NextDomain), Salt: record.Salt, Iterations: record.Iterations}
+			order = append(order, hash)
+		}
+	}
+	complete := func() bool {
+		for _, hash := range hashes {
+			if _, ok := hashes[hash.Next]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	var err error
+	add(records)
+	for queries := 0; queries < limit && !complete(); queries++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		var answer *dns.Msg
+		answer, err = w.query(xid.New().String()+"."+apex, dns.TypeA)
+		if err != nil {
+			break
+		}
+		add(findNSEC3(answer.Ns))
+	}
+
+	result := &WalkResult{}
+	for _, hash := range order {
+		result.Hashes = append(result.Hashes, hashes[hash])
+	}
+	return result, err
+}
+
+// findNSEC returns the NSEC record owned by the name among the records
+func findNSEC(records []dns.RR, name string) *dns.NSEC {
+	for _, record := range records {
+		if nsec, ok := record.(*dns.NSEC); ok && strings.EqualFold(nsec.Hdr.Name, name) {
+			return nsec
+		}
+	}
+	return nil
+}
+
+// findNSEC3 returns the NSEC3 records among the records
+func findNSEC3(records []dns.RR) []*dns.NSEC3 {
+	var nsec3 []*dns.NSEC3
+	for _, record := range records {
+		if record, ok := record.(*dns.NSEC3); ok {
+			nsec3 = append(nsec3, record)
+		}
+	}
+	return nsec3
+}
@@ -0,0 +1,87 @@
+// Package zone enumerates the names of a zone directly from its
+// authoritative nameservers, with zone transfers and DNSSEC zone walking.
+package zone
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Transfer requests a zone transfer (AXFR) of the zone from the nameserver
+// and returns the names of the zone, along with the in-zone targets of its
+// CNAME, MX, NS and SRV records.
+func Transfer(ctx context.Context, nameserver, zone string, timeout time.Duration) ([]string, error) {
+	transfer := &dns.Transfer{DialTimeout: timeout, ReadTimeout: timeout, WriteTimeout: timeout}
+	envelopes, err := transfer.In(new(dns.Msg).SetAxfr(dns.Fqdn(zone)), nameserver)
+	if err != nil {
+		return nil, err
+	}
+	// Drain the transfer in case it is left before its end, so that it does not block
+	defer func() {
+		go func() {
+			for range envelopes {
+			}
+		}()
+	}()
+
+	names := newNameSet(zone)
+	for {
+		select {
+		case <-ctx.Done():
+			return names.list, ctx.Err()
+		case envelope, ok := <-envelopes:
+			if !ok {
+				return names.list, nil
+			}
+			if envelope.Error != nil {
+				return names.list, envelope.Error
+			}
+			for _, record := range envelope.RR {
+				names.add(record.Header().Name)
+				names.add(target(record))
+			}
+		}
+	}
+}
+
+// target returns the name a record points to, if any
+func target(record dns.RR) string {
+	switch record := record.(type) {
+	case *dns.CNAME:
+		return record.Target
+	case *dns.MX:
+		return record.Mx
+	case *dns.NS:
+		return record.Ns
+	case *dns.SRV:
+		return record.Target
+	}
+	return ""
+}
+
+// nameSet keeps the distinct names under a zone in the order they are added
+type nameSet struct {
+	zone string
+	seen map[string]struct{}
+	list []string
+}
+
+func newNameSet(zone string) *nameSet {
+	return &nameSet{zone: strings.ToLower(strings.TrimSuffix(zone, ".")), seen: make(map[string]struct{})}
+}
+
+// add records the name if it is a new name under the zone, the zone itself left out
+func (s *nameSet) add(name string) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if !strings.HasSuffix(name, "."+s.zone) {
+		return
+	}
+	if _, ok := s.seen[name]; ok {
+		return
+	}
+	s.seen[name] = struct{}{}
+	s.list = append(s.list, name)
+}
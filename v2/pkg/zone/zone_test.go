@@ -0,0 +1,128 @@
+package zone
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testZone contains the names of the zone served by the test nameserver, in canonical order
+var testZone = []string{"example.com.", "a.example.com.", "b.example.com.", "c.example.com."}
+
+// startNameserver starts a local nameserver over TCP answering with the handler
+func startNameserver(t *testing.T, handler dns.HandlerFunc) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	server := &dns.Server{Listener: listener, Handler: handler}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return listener.Addr().String()
+}
+
+// nsecHandler serves the test zone signed with NSEC, allowing zone transfers if asked to
+func nsecHandler(allowTransfer bool) dns.HandlerFunc {
+	return func(w dns.ResponseWriter, req *dns.Msg) {
+		question := req.Question[0]
+		msg := new(dns.Msg).SetReply(req)
+
+		if question.Qtype == dns.TypeAXFR {
+			if !allowTransfer {
+				_ = w.WriteMsg(msg.SetRcode(req, dns.RcodeRefused))
+				return
+			}
+			records := []dns.RR{&dns.SOA{Hdr: dns.RR_Header{Name: testZone[0], Rrtype: dns.TypeSOA, Class: dns.ClassINET}, Ns: "ns.example.com.", Mbox: "admin.example.com."}}
+			for _, name := range testZone[1:] {
+				records = append(records, &dns.A{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET}, A: net.ParseIP("192.0.2.1")})
+			}
+			records = append(records, &dns.CNAME{Hdr: dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET}, Target: "cdn.example.net."})
+			records = append(records, records[0])
+
+			ch := make(chan *dns.Envelope)
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = new(dns.Transfer).Out(w, req, ch)
+			}()
+			ch <- &dns.Envelope{RR: records}
+			close(ch)
+			wg.Wait()
+			return
+		}
+
+		for i, name := range testZone {
+			if question.Qtype == dns.TypeNSEC && question.Name == name {
+				msg.Answer = append(msg.Answer, &dns.NSEC{
+					Hdr:        dns.RR_Header{Name: name, Rrtype: dns.TypeNSEC, Class: dns.ClassINET},
+					NextDomain: testZone[(i+1)%len(testZone)],
+				})
+			}
+		}
+		_ = w.WriteMsg(msg)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	nameserver := startNameserver(t, nsecHandler(true))
+
+	names, err := Transfer(context.Background(), nameserver, "example.com", time.Second)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com", "www.example.com"}, names)
+}
+
+func TestTransferRefused(t *testing.T) {
+	nameserver := startNameserver(t, nsecHandler(false))
+
+	names, err := Transfer(context.Background(), nameserver, "example.com", time.Second)
+	require.NotNil(t, err)
+	assert.Empty(t, names)
+}
+
+func TestWalkNSEC(t *testing.T) {
+	nameserver := startNameserver(t, nsecHandler(false))
+
+	result, err := Walk(context.Background(), nameserver, "example.com", time.Second, 100)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com"}, result.Names)
+	assert.Empty(t, result.Hashes)
+}
+
+func TestWalkNSEC3(t *testing.T) {
+	chain := []string{"0p9mhaveqvm6t7vbl5lop2u3t2rp3tom", "2vptu5timamqttgl4luu9kg21e0aor3s", "k8udemvp1j2f7eg6jebps17vp3n8i58h"}
+	var mutex sync.Mutex
+	queries := 0
+	nameserver := startNameserver(t, func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg).SetReply(req)
+		if req.Question[0].Qtype != dns.TypeNSEC {
+			mutex.Lock()
+			i := queries % len(chain)
+			queries++
+			mutex.Unlock()
+
+			msg.Rcode = dns.RcodeNameError
+			msg.Ns = append(msg.Ns, &dns.NSEC3{
+				Hdr:        dns.RR_Header{Name: chain[i] + ".example.com.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET},
+				Hash:       dns.SHA1,
+				Iterations: 1,
+				SaltLength: 2,
+				Salt:       "ABCD",
+				HashLength: 20,
+				NextDomain: chain[(i+1)%len(chain)],
+			})
+		}
+		_ = w.WriteMsg(msg)
+	})
+
+	result, err := Walk(context.Background(), nameserver, "example.com", time.Second, 100)
+	require.Nil(t, err)
+	assert.Empty(t, result.Names)
+	require.Len(t, result.Hashes, len(chain))
+	assert.Equal(t, "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom:.example.com:abcd:1", result.Hashes[0].Format("example.com"))
+}